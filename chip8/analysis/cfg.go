@@ -0,0 +1,463 @@
+// Package analysis works out the structure of chip-8 programs statically, without running them:
+// the control-flow graph of a ROM, its subroutines, the code no path gets to and the
+// instructions that write over the program itself.
+package analysis
+
+import (
+	"sort"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+// EdgeKind: how the control gets from the end of a block to another
+type EdgeKind int
+
+const (
+	// Fallthrough: the next instruction runs, also when a skip doesn't skip
+	Fallthrough EdgeKind = iota
+	// Jump: JP nnn
+	Jump
+	// Skip: a skip instruction skipped the next instruction
+	Skip
+	// Call: CALL nnn goes into a subroutine
+	Call
+	// Return: the subroutine called at the end of the block returned to the instruction after the call
+	Return
+)
+
+var edgeKindNames = [...]string{"fallthrough", "jump", "skip", "call", "return"}
+
+func (k EdgeKind) String() string {
+	return edgeKindNames[k]
+}
+
+type Edge struct {
+	From, To uint16
+	Kind     EdgeKind
+}
+
+// Block is a basic block, instructions that always run one after the other. Only its last
+// instruction can change the control flow, and only its first one is the target of jumps.
+type Block struct {
+	Start uint16
+	// End: the address right after the last instruction
+	End          uint16
+	Instructions []chip8.Instruction
+	Successors   []Edge
+	Predecessors []Edge
+}
+
+// Address: where the i-th instruction of the block is
+func (b *Block) Address(i int) uint16 {
+	return b.Start + uint16(i*2)
+}
+
+// Last: the instruction that ends the block
+func (b *Block) Last() chip8.Instruction {
+	return b.Instructions[len(b.Instructions)-1]
+}
+
+// Subroutine: the blocks reachable from an entry point without going into the subroutines it calls
+type Subroutine struct {
+	Entry uint16
+	// Blocks: the starts of the blocks, sorted
+	Blocks []uint16
+	// Callers: the addresses of the CALLs to the entry
+	Callers []uint16
+	// Calls: the entries of the subroutines called, sorted
+	Calls []uint16
+	// Returns: true if a RET is reachable from the entry
+	Returns bool
+}
+
+// Region: a range of bytes of the ROM, from Start to End exclusive
+type Region struct {
+	Start, End uint16
+	// Referenced: a reachable LD I, nnn points inside, so it's most likely data the program
+	// reads rather than dead code
+	Referenced bool
+}
+
+// Write: an Fx55 or Fx33 instruction, which writes to memory at I
+type Write struct {
+	PC     uint16
+	Opcode uint16
+	// Known: the analysis worked out the value of I, and so Start and End, the bytes written
+	Known      bool
+	Start, End uint16
+	// Code: the bytes written include the bytes of reachable instructions
+	Code bool
+}
+
+// Graph is the control-flow graph of a ROM, from its start address
+type Graph struct {
+	Start uint16
+	// Blocks: every reachable block, sorted by address
+	Blocks []*Block
+	// Main: the blocks reachable from the start without going into subroutines
+	Main *Subroutine
+	// Subroutines: the subroutines called by reachable CALLs, sorted by entry
+	Subroutines []*Subroutine
+	// ComputedJumps: the addresses of the JP V0, nnn instructions, the analysis can't
+	// follow them so the code they go to may show up as unreachable
+	ComputedJumps []uint16
+	// DeadEnds: instructions that aren't chip-8 ones or run past the end of memory. Paths
+	// stop there, they're usually data reached by a path the program never takes.
+	DeadEnds []uint16
+	// Writes: every reachable Fx55 and Fx33, sorted by address
+	Writes []Write
+	// Unreachable: the bytes of the ROM no path executes
+	Unreachable []Region
+
+	memory       [chip8.MemorySize]uint8
+	instructions map[uint16]chip8.Instruction
+	leaders      map[uint16]bool
+	code         [chip8.MemorySize]bool
+	blocks       map[uint16]*Block
+	subroutines  map[uint16]*Subroutine
+	pointers     map[uint16]bool
+}
+
+// Analyze: builds the control-flow graph of the ROM loaded at loadAddress, where the program starts
+func Analyze(rom []uint8, loadAddress uint16) *Graph {
+	g := &Graph{
+		Start:        loadAddress,
+		instructions: map[uint16]chip8.Instruction{},
+		leaders:      map[uint16]bool{},
+		blocks:       map[uint16]*Block{},
+		subroutines:  map[uint16]*Subroutine{},
+		pointers:     map[uint16]bool{},
+	}
+	copy(g.memory[loadAddress:], rom)
+
+	g.explore()
+	g.buildBlocks()
+	g.findSubroutines()
+	g.findWrites()
+	g.findUnreachable(loadAddress, len(rom))
+	return g
+}
+
+// Block: the block that starts at addr, nil if there's none
+func (g *Graph) Block(addr uint16) *Block {
+	return g.blocks[addr]
+}
+
+// Subroutine: the subroutine that starts at entry, nil if nothing calls it
+func (g *Graph) Subroutine(entry uint16) *Subroutine {
+	return g.subroutines[entry]
+}
+
+// IsCode: true if addr is a byte of a reachable instruction
+func (g *Graph) IsCode(addr uint16) bool {
+	return int(addr) < chip8.MemorySize && g.code[addr]
+}
+
+// SelfModifying: the writes that change the bytes of reachable instructions
+func (g *Graph) SelfModifying() []Write {
+	var writes []Write
+	for _, w := range g.Writes {
+		if w.Code {
+			writes = append(writes, w)
+		}
+	}
+	return writes
+}
+
+// endsBlock: true if the instruction changes the control flow, so it's the last of its block
+func endsBlock(op chip8.Operation) bool {
+	switch op {
+	case chip8.OpJP, chip8.OpCALL, chip8.OpRET, chip8.OpJPV0, chip8.OpSYS, chip8.OpInvalid:
+		return true
+	}
+	return isSkip(op)
+}
+
+func isSkip(op chip8.Operation) bool {
+	switch op {
+	case chip8.OpSEByte, chip8.OpSNEByte, chip8.OpSERegister, chip8.OpSNERegister, chip8.OpSKP, chip8.OpSKNP:
+		return true
+	}
+	return false
+}
+
+// explore: decodes every instruction reachable from the start, and marks the leaders,
+// the instructions where blocks start
+func (g *Graph) explore() {
+	work := []uint16{g.Start}
+	pastMemory := map[uint16]bool{}
+	g.leaders[g.Start] = true
+	lead := func(addr uint16) {
+		if !g.leaders[addr] {
+			g.leaders[addr] = true
+			work = append(work, addr)
+		}
+	}
+
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		for {
+			if _, seen := g.instructions[addr]; seen {
+				break
+			}
+			if int(addr)+1 >= chip8.MemorySize {
+				if !pastMemory[addr] {
+					pastMemory[addr] = true
+					g.DeadEnds = append(g.DeadEnds, addr)
+				}
+				break
+			}
+			in := chip8.Decode(uint16(g.memory[addr])<<chip8.ByteSize | uint16(g.memory[addr+1]))
+			g.instructions[addr] = in
+			g.code[addr], g.code[addr+1] = true, true
+			next := addr + 2
+
+			switch {
+			case in.Op == chip8.OpJP:
+				lead(in.NNN)
+			case in.Op == chip8.OpCALL:
+				lead(in.NNN)
+				lead(next)
+			case in.Op == chip8.OpJPV0:
+				g.ComputedJumps = append(g.ComputedJumps, addr)
+			case in.Op == chip8.OpSYS, in.Op == chip8.OpInvalid:
+				g.DeadEnds = append(g.DeadEnds, addr)
+			case isSkip(in.Op):
+				lead(next)
+				lead(next + 2)
+			case in.Op == chip8.OpLDI:
+				g.pointers[in.NNN] = true
+			}
+			if endsBlock(in.Op) {
+				break
+			}
+			addr = next
+		}
+	}
+
+	sortAddresses(g.ComputedJumps)
+	sortAddresses(g.DeadEnds)
+}
+
+// buildBlocks: splits the instructions into blocks at the leaders and links them
+func (g *Graph) buildBlocks() {
+	for start := range g.leaders {
+		if _, ok := g.instructions[start]; !ok {
+			continue
+		}
+		b := &Block{Start: start}
+		for addr := start; ; addr += 2 {
+			in, ok := g.instructions[addr]
+			if !ok || (addr != start && g.leaders[addr]) {
+				break
+			}
+			b.Instructions = append(b.Instructions, in)
+			b.End = addr + 2
+			if endsBlock(in.Op) {
+				break
+			}
+		}
+		g.blocks[start] = b
+		g.Blocks = append(g.Blocks, b)
+	}
+	sort.Slice(g.Blocks, func(i, j int) bool {
+		return g.Blocks[i].Start < g.Blocks[j].Start
+	})
+
+	for _, b := range g.Blocks {
+		last := b.Last()
+		switch {
+		case last.Op == chip8.OpJP:
+			g.link(b, last.NNN, Jump)
+		case last.Op == chip8.OpCALL:
+			g.link(b, last.NNN, Call)
+			g.link(b, b.End, Return)
+		case isSkip(last.Op):
+			g.link(b, b.End, Fallthrough)
+			g.link(b, b.End+2, Skip)
+		case !endsBlock(last.Op):
+			g.link(b, b.End, Fallthrough)
+		}
+	}
+}
+
+func (g *Graph) link(from *Block, to uint16, kind EdgeKind) {
+	target, ok := g.blocks[to]
+	if !ok {
+		return
+	}
+	edge := Edge{From: from.Start, To: to, Kind: kind}
+	from.Successors = append(from.Successors, edge)
+	target.Predecessors = append(target.Predecessors, edge)
+}
+
+// findSubroutines: groups the blocks by the subroutine they're reachable from
+func (g *Graph) findSubroutines() {
+	g.Main = g.subroutine(g.Start)
+	for _, b := range g.Blocks {
+		for _, edge := range b.Successors {
+			if edge.Kind != Call {
+				continue
+			}
+			s, ok := g.subroutines[edge.To]
+			if !ok {
+				s = g.subroutine(edge.To)
+				g.subroutines[edge.To] = s
+				g.Subroutines = append(g.Subroutines, s)
+			}
+			s.Callers = append(s.Callers, b.Address(len(b.Instructions)-1))
+		}
+	}
+	sort.Slice(g.Subroutines, func(i, j int) bool {
+		return g.Subroutines[i].Entry < g.Subroutines[j].Entry
+	})
+}
+
+func (g *Graph) subroutine(entry uint16) *Subroutine {
+	s := &Subroutine{Entry: entry}
+	seen := map[uint16]bool{entry: true}
+	calls := map[uint16]bool{}
+	work := []uint16{entry}
+	for len(work) > 0 {
+		b := g.blocks[work[len(work)-1]]
+		work = work[:len(work)-1]
+		s.Blocks = append(s.Blocks, b.Start)
+		if b.Last().Op == chip8.OpRET {
+			s.Returns = true
+		}
+		for _, edge := range b.Successors {
+			if edge.Kind == Call {
+				calls[edge.To] = true
+				continue
+			}
+			if !seen[edge.To] {
+				seen[edge.To] = true
+				work = append(work, edge.To)
+			}
+		}
+	}
+	for call := range calls {
+		s.Calls = append(s.Calls, call)
+	}
+	sortAddresses(s.Blocks)
+	sortAddresses(s.Calls)
+	return s
+}
+
+// indexValue: what the analysis knows about I at some point, a value or that it can't be known
+type indexValue struct {
+	known bool
+	value uint16
+}
+
+var unknownIndex = &indexValue{}
+
+// meet: the value of I where two paths join, unknown if they disagree
+func meet(a, b *indexValue) *indexValue {
+	switch {
+	case a == nil:
+		return b
+	case b == nil || *a == *b:
+		return a
+	}
+	return unknownIndex
+}
+
+// runIndex: the value of I after each instruction of the block, given the value it starts with
+func runIndex(b *Block, entry *indexValue, visit func(addr uint16, in chip8.Instruction, i *indexValue)) *indexValue {
+	i := entry
+	for n, in := range b.Instructions {
+		if visit != nil {
+			visit(b.Address(n), in, i)
+		}
+		switch in.Op {
+		case chip8.OpLDI:
+			i = &indexValue{known: true, value: in.NNN}
+		case chip8.OpADDI, chip8.OpLDF, chip8.OpLDIVx, chip8.OpLDVxI:
+			// Fx55 and Fx65 move I on some platforms only
+			i = unknownIndex
+		}
+	}
+	return i
+}
+
+// findWrites: works out I on every Fx55 and Fx33 propagating the values set by LD I, nnn
+// through the graph. I is 0 on reset, and unknown after calls since subroutines can change it.
+func (g *Graph) findWrites() {
+	entries := map[uint16]*indexValue{g.Start: {known: true}}
+	work := []uint16{g.Start}
+	for len(work) > 0 {
+		b := g.blocks[work[len(work)-1]]
+		work = work[:len(work)-1]
+		exit := runIndex(b, entries[b.Start], nil)
+		for _, edge := range b.Successors {
+			value := exit
+			if edge.Kind == Return {
+				value = unknownIndex
+			}
+			joined := meet(entries[edge.To], value)
+			if previous, ok := entries[edge.To]; !ok || *previous != *joined {
+				entries[edge.To] = joined
+				work = append(work, edge.To)
+			}
+		}
+	}
+
+	for _, b := range g.Blocks {
+		entry := entries[b.Start]
+		if entry == nil {
+			entry = unknownIndex
+		}
+		runIndex(b, entry, func(addr uint16, in chip8.Instruction, i *indexValue) {
+			var size uint16
+			switch in.Op {
+			case chip8.OpLDIVx:
+				size = uint16(in.X) + 1
+			case chip8.OpLDB:
+				size = 3
+			default:
+				return
+			}
+			w := Write{PC: addr, Opcode: in.Opcode, Known: i.known}
+			if w.Known {
+				w.Start, w.End = i.value, i.value+size
+				for a := w.Start; a < w.End; a++ {
+					if g.IsCode(a) {
+						w.Code = true
+					}
+				}
+			}
+			g.Writes = append(g.Writes, w)
+		})
+	}
+}
+
+// findUnreachable: the runs of bytes of the ROM that aren't part of reachable instructions
+func (g *Graph) findUnreachable(loadAddress uint16, size int) {
+	end := int(loadAddress) + size
+	if end > chip8.MemorySize {
+		end = chip8.MemorySize
+	}
+	for addr := int(loadAddress); addr < end; addr++ {
+		if g.code[addr] {
+			continue
+		}
+		r := Region{Start: uint16(addr)}
+		for addr < end && !g.code[addr] {
+			if g.pointers[uint16(addr)] {
+				r.Referenced = true
+			}
+			addr++
+		}
+		r.End = uint16(addr)
+		g.Unreachable = append(g.Unreachable, r)
+	}
+}
+
+func sortAddresses(addrs []uint16) {
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i] < addrs[j]
+	})
+}
@@ -0,0 +1,149 @@
+package analysis
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/franciscocid/chip-8/chip8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	rom := []uint8{
+		0x60, 0x00, // 200 LD V0, 0
+		0x22, 0x10, // 202 CALL 0x210
+		0x30, 0x05, // 204 SE V0, 5
+		0x12, 0x02, // 206 JP 0x202
+		0x12, 0x08, // 208 JP 0x208
+		0x00, 0x00, // 20A never runs
+		0x00, 0xE0, // 20C
+		0x00, 0x00, // 20E
+		0x70, 0x01, // 210 ADD V0, 1
+		0xA2, 0x1A, // 212 LD I, 0x21A
+		0xF0, 0x33, // 214 LD B, V0
+		0x00, 0xEE, // 216 RET
+		0x00, 0x00, // 218
+		0x00, 0x00, 0x00, 0x00, // 21A the digits of V0
+	}
+	g := Analyze(rom, chip8.ProgramStartAddress)
+
+	t.Run("Analyze should split the code in basic blocks", func(t *testing.T) {
+		var starts []uint16
+		for _, b := range g.Blocks {
+			starts = append(starts, b.Start)
+		}
+		assert.Equal(t, []uint16{0x200, 0x202, 0x204, 0x206, 0x208, 0x210}, starts)
+		assert.Len(t, g.Block(0x210).Instructions, 4)
+		assert.Equal(t, uint16(0x218), g.Block(0x210).End)
+		assert.Nil(t, g.Block(0x20A))
+	})
+
+	t.Run("Analyze should link the blocks with the edges of jumps, skips and calls", func(t *testing.T) {
+		assert.Equal(t, []Edge{{0x200, 0x202, Fallthrough}}, g.Block(0x200).Successors)
+		assert.Equal(t, []Edge{{0x202, 0x210, Call}, {0x202, 0x204, Return}}, g.Block(0x202).Successors)
+		assert.Equal(t, []Edge{{0x204, 0x206, Fallthrough}, {0x204, 0x208, Skip}}, g.Block(0x204).Successors)
+		assert.Equal(t, []Edge{{0x206, 0x202, Jump}}, g.Block(0x206).Successors)
+		assert.Equal(t, []Edge{{0x208, 0x208, Jump}}, g.Block(0x208).Successors)
+		assert.Empty(t, g.Block(0x210).Successors, "Should end subroutines on RET")
+		assert.Equal(t, []Edge{{0x200, 0x202, Fallthrough}, {0x206, 0x202, Jump}}, g.Block(0x202).Predecessors)
+	})
+
+	t.Run("Analyze should find the subroutines and what they call", func(t *testing.T) {
+		assert.Equal(t, &Subroutine{Entry: 0x200, Blocks: []uint16{0x200, 0x202, 0x204, 0x206, 0x208}, Calls: []uint16{0x210}}, g.Main)
+		assert.Equal(t, []*Subroutine{{Entry: 0x210, Blocks: []uint16{0x210}, Callers: []uint16{0x202}, Returns: true}}, g.Subroutines)
+		assert.Equal(t, g.Subroutines[0], g.Subroutine(0x210))
+	})
+
+	t.Run("Analyze should find the bytes no path executes", func(t *testing.T) {
+		assert.Equal(t, []Region{{0x20A, 0x210, false}, {0x218, 0x21E, true}}, g.Unreachable)
+		assert.True(t, g.IsCode(0x211))
+		assert.False(t, g.IsCode(0x21A))
+	})
+
+	t.Run("Analyze should follow I to the writes of the program", func(t *testing.T) {
+		assert.Equal(t, []Write{{PC: 0x214, Opcode: 0xF033, Known: true, Start: 0x21A, End: 0x21D}}, g.Writes)
+		assert.Empty(t, g.SelfModifying())
+	})
+
+	t.Run("Analyze should flag computed jumps and writes over the code", func(t *testing.T) {
+		g := Analyze([]uint8{
+			0xA2, 0x00, // 200 LD I, 0x200
+			0xF1, 0x55, // 202 LD [I], V1
+			0xB2, 0x08, // 204 JP V0, 0x208
+			0x00, 0x00, // 206
+			0x12, 0x08, // 208 JP 0x208
+		}, chip8.ProgramStartAddress)
+		assert.Equal(t, []uint16{0x204}, g.ComputedJumps)
+		assert.Equal(t, []Write{{PC: 0x202, Opcode: 0xF155, Known: true, Start: 0x200, End: 0x202, Code: true}}, g.SelfModifying())
+		assert.Equal(t, []Region{{0x206, 0x20A, false}}, g.Unreachable)
+	})
+
+	t.Run("Analyze shouldn't know I after a call or where paths set it to different values", func(t *testing.T) {
+		g := Analyze([]uint8{
+			0xA3, 0x00, // 200 LD I, 0x300
+			0x22, 0x10, // 202 CALL 0x210
+			0xF0, 0x55, // 204 LD [I], V0
+			0xA3, 0x00, // 206 LD I, 0x300
+			0x30, 0x00, // 208 SE V0, 0
+			0xA3, 0x10, // 20A LD I, 0x310
+			0xF0, 0x33, // 20C LD B, V0
+			0x12, 0x0E, // 20E JP 0x20E
+			0x00, 0xEE, // 210 RET
+		}, chip8.ProgramStartAddress)
+		assert.Equal(t, []Write{{PC: 0x204, Opcode: 0xF055}, {PC: 0x20C, Opcode: 0xF033}}, g.Writes)
+	})
+
+	t.Run("Analyze should stop at instructions that aren't chip-8 ones", func(t *testing.T) {
+		g := Analyze([]uint8{0x30, 0x00, 0x01, 0x23, 0x12, 0x04}, chip8.ProgramStartAddress)
+		assert.Equal(t, []uint16{0x202}, g.DeadEnds)
+		assert.Empty(t, g.Block(0x202).Successors)
+	})
+
+	t.Run("WriteDOT should write a node per block and an edge per successor", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, g.WriteDOT(&b))
+		dot := b.String()
+		assert.Contains(t, dot, "digraph cfg {")
+		assert.Contains(t, dot, `b200 [label="main:\l200  LD V0, 0x00\l" peripheries=2];`)
+		assert.Contains(t, dot, `b210 [label="sub_210:\l210  ADD V0, 0x01\l212  LD I, 0x21A\l214  LD B, V0\l216  RET\l" peripheries=2];`)
+		assert.Contains(t, dot, `b202 -> b210 [label="call" style="dashed"];`)
+		assert.Contains(t, dot, `b204 -> b208 [label="skip" color="blue"];`)
+		assert.Contains(t, dot, "b200 -> b202;")
+	})
+}
+
+// TestAnalyzeROMs runs the bundled ROMs and checks the graph has every instruction they run
+func TestAnalyzeROMs(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "roms", "*.ch8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			rom, err := chip8.LoadROMFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := chip8.New()
+			if err := c.LoadROM(rom); err != nil {
+				t.Fatal(err)
+			}
+			g := Analyze(rom.Data, c.Platform.LoadAddress)
+
+			missed := map[uint16]bool{}
+			chip8.RecordTrace(c, func(e chip8.TraceEntry) {
+				if !g.IsCode(e.PC) {
+					missed[e.PC] = true
+				}
+			})
+			ctl := chip8.NewController(c)
+			for frame := 0; frame < 300; frame++ {
+				ctl.StepFrame()
+			}
+			if len(g.ComputedJumps) == 0 && len(g.SelfModifying()) == 0 {
+				assert.Empty(t, missed, "Should have found every instruction the ROM ran")
+			}
+		})
+	}
+}
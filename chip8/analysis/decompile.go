@@ -0,0 +1,455 @@
+package analysis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+// Decompile: writes the program as Octo-style source (http://johnearnest.github.io/Octo/docs/Manual.html).
+// Backward jumps become loop ... again, the skips over jumps out of loops become while, the skips
+// over the jump back become if ... then again, and the skips over a single instruction or over a
+// jump past a block become if ... then and if ... begin ... else ... end. Whatever doesn't fit those
+// shapes is kept as jumps to labels, and the jumps to the code that follows are kept too, so the
+// program runs as many instructions as the ROM. The unreachable bytes are appended as data at the
+// end, split wherever an LD I points so every pointer gets a label that moves along with its bytes,
+// and LD I pointing at code gets the label of the instruction. Assembling the source doesn't give
+// back the same ROM, but a program that runs like it as long as it doesn't read past the end of a
+// region or compute addresses some other way.
+func (g *Graph) Decompile(w io.Writer) error {
+	d := &decompiler{g: g, names: map[uint16]string{}, used: map[uint16]bool{}, owned: map[uint16]bool{}, absorbed: map[uint16]bool{}}
+	d.names[g.Start] = "main"
+	for _, s := range g.Subroutines {
+		d.names[s.Entry] = fmt.Sprintf("sub_%03X", s.Entry)
+	}
+	d.regions = g.dataRegions()
+	for _, r := range d.regions {
+		d.names[r.Start] = fmt.Sprintf("data_%03X", r.Start)
+	}
+
+	d.routine(g.Main)
+	for _, s := range g.Subroutines {
+		d.routine(s)
+	}
+	d.data()
+	return d.print(w)
+}
+
+// noNext: where a region continues when nothing comes after it
+const noNext uint16 = 0xFFFF
+
+type decompiler struct {
+	g *Graph
+	// names: the routines and data regions, they're always labelled
+	names map[uint16]string
+	// used: the addresses some emitted jump goes to
+	used map[uint16]bool
+	// owned: the blocks already emitted, blocks shared by routines go with the first one
+	owned map[uint16]bool
+	// absorbed: the jumps again and else already emit
+	absorbed map[uint16]bool
+	// againIf: the condition of the skip over the jump back of the loop being emitted, if any
+	againIf string
+	// regions: the unreachable bytes, split at the addresses LD I points to
+	regions []Region
+	lines   []decompiledLine
+}
+
+// decompiledLine: a line of source, or the place of the label of a block if it turns out to be used
+type decompiledLine struct {
+	depth int
+	text  string
+	label bool
+	addr  uint16
+}
+
+// pointerMark: starts the placeholder of the address an LD I points to, print puts the label
+// of the address in its place if one was emitted, the address itself otherwise
+const pointerMark = "\x00"
+
+// dataRegions: the unreachable regions, split at the addresses LD I points to
+func (g *Graph) dataRegions() []Region {
+	var regions []Region
+	for _, r := range g.Unreachable {
+		start := r.Start
+		for addr := r.Start + 1; addr <= r.End; addr++ {
+			if addr == r.End || g.pointers[addr] {
+				regions = append(regions, Region{Start: start, End: addr, Referenced: r.Referenced})
+				start = addr
+			}
+		}
+	}
+	return regions
+}
+
+// loopContext: the loop ... again being emitted, exit is the address after its last block
+type loopContext struct {
+	head, exit uint16
+	// canExit: the code after again is the exit, so while can be used
+	canExit bool
+}
+
+// condition: what makes a skip instruction skip, and the opposite
+type condition struct {
+	skips, runs string
+}
+
+func (d *decompiler) add(depth int, text string) {
+	d.lines = append(d.lines, decompiledLine{depth: depth, text: text})
+}
+
+func (d *decompiler) mark(addr uint16, depth int) {
+	d.lines = append(d.lines, decompiledLine{depth: depth, label: true, addr: addr})
+}
+
+// ref: the name of the address, which from now on needs its label
+func (d *decompiler) ref(addr uint16) string {
+	d.used[addr] = true
+	return d.name(addr)
+}
+
+func (d *decompiler) name(addr uint16) string {
+	if name, ok := d.names[addr]; ok {
+		return name
+	}
+	return fmt.Sprintf("label_%03X", addr)
+}
+
+// routine: emits the blocks of the subroutine no other routine emitted yet, in address order
+func (d *decompiler) routine(s *Subroutine) {
+	var blocks []*Block
+	for _, start := range s.Blocks {
+		if !d.owned[start] {
+			d.owned[start] = true
+			blocks = append(blocks, d.g.Block(start))
+		}
+	}
+	if len(blocks) == 0 {
+		return
+	}
+	if len(d.lines) > 0 {
+		d.add(0, "")
+	}
+	d.structure(blocks, 0, len(blocks), noNext, nil, 1)
+}
+
+// natural: the address the code emitted after blocks[i] starts with, next if it's the end of the region
+func natural(blocks []*Block, i, hi int, next uint16) uint16 {
+	if i < hi {
+		return blocks[i].Start
+	}
+	return next
+}
+
+// structure: emits the blocks in [lo, hi), next is the address that runs after the last one
+func (d *decompiler) structure(blocks []*Block, lo, hi int, next uint16, loops []loopContext, depth int) {
+	for i := lo; i < hi; {
+		b := blocks[i]
+		if t := loopTail(blocks, i, hi, loops); t >= 0 {
+			exit := blocks[t].End
+			loop := loopContext{head: b.Start, exit: exit, canExit: natural(blocks, t+1, hi, next) == exit}
+			d.absorbed[exit-2] = true
+			d.add(depth, "loop")
+			d.structure(blocks, i, t+1, b.Start, append(loops[:len(loops):len(loops)], loop), depth+1)
+			if d.againIf != "" {
+				d.add(depth, "if "+d.againIf+" then again")
+				d.againIf = ""
+			} else {
+				d.add(depth, "again")
+			}
+			i = t + 1
+			continue
+		}
+		i = d.block(blocks, i, hi, next, loops, depth)
+	}
+}
+
+// loopTail: the last block of the region that jumps back to blocks[i], -1 if there's none
+// or blocks[i] is already the head of a loop being emitted
+func loopTail(blocks []*Block, i, hi int, loops []loopContext) int {
+	head := blocks[i].Start
+	for _, loop := range loops {
+		if loop.head == head {
+			return -1
+		}
+	}
+	for t := hi - 1; t >= i; t-- {
+		if last := blocks[t].Last(); last.Op == chip8.OpJP && last.NNN == head {
+			return t
+		}
+	}
+	return -1
+}
+
+// block: emits blocks[i] and returns the index of the next block to emit, which is further
+// on when the block starts an if
+func (d *decompiler) block(blocks []*Block, i, hi int, next uint16, loops []loopContext, depth int) int {
+	b := blocks[i]
+	d.mark(b.Start, depth)
+	for j, in := range b.Instructions[:len(b.Instructions)-1] {
+		if j > 0 && d.g.pointers[b.Address(j)] {
+			d.mark(b.Address(j), depth)
+		}
+		d.add(depth, d.statement(in))
+	}
+	if len(b.Instructions) > 1 && d.g.pointers[b.Address(len(b.Instructions)-1)] {
+		d.mark(b.Address(len(b.Instructions)-1), depth)
+	}
+
+	last := b.Last()
+	following := natural(blocks, i+1, hi, next)
+	switch {
+	case isSkip(last.Op):
+		return d.skip(blocks, i, hi, next, loops, depth)
+	case last.Op == chip8.OpJP:
+		if !d.absorbed[b.End-2] {
+			d.add(depth, d.statement(last))
+		}
+	case endsBlock(last.Op):
+		d.add(depth, d.statement(last))
+	default:
+		d.add(depth, d.statement(last))
+		d.continueAt(b.End, following, depth)
+	}
+	return i + 1
+}
+
+// continueAt: jumps to addr unless it's the code that comes next anyway
+func (d *decompiler) continueAt(addr, following uint16, depth int) {
+	if addr != following && d.g.Block(addr) != nil {
+		d.add(depth, "jump "+d.ref(addr))
+	}
+}
+
+// skip: emits the skip instruction that ends blocks[i] together with what it skips
+func (d *decompiler) skip(blocks []*Block, i, hi int, next uint16, loops []loopContext, depth int) int {
+	b := blocks[i]
+	cond := skipCondition(b.Last())
+	skipped, target := b.End, b.End+2
+
+	// skipping the jump back: Octo's while would add a jump out of the loop, so the skip goes with again
+	if len(loops) > 0 && target == loops[len(loops)-1].exit && i+1 < hi && blocks[i+1].Start == skipped {
+		d.againIf = cond.runs
+		return i + 1
+	}
+
+	var loop *loopContext
+	if len(loops) > 0 && loops[len(loops)-1].canExit {
+		loop = &loops[len(loops)-1]
+	}
+
+	// the skipped instruction goes inside an if only when it's the only way into its block,
+	// and it can't be another skip since Octo has no statement for those
+	if i+1 >= hi || blocks[i+1].Start != skipped || len(blocks[i+1].Instructions) != 1 ||
+		len(blocks[i+1].Predecessors) != 1 || isSkip(blocks[i+1].Last().Op) {
+		d.add(depth, "if "+cond.skips+" then jump "+d.ref(target))
+		d.continueAt(skipped, natural(blocks, i+1, hi, next), depth)
+		return i + 1
+	}
+	in := blocks[i+1].Instructions[0]
+	after := natural(blocks, i+2, hi, next)
+
+	switch {
+	case in.Op == chip8.OpJP && loop != nil && in.NNN == loop.exit:
+		d.add(depth, "while "+cond.skips)
+		d.continueAt(target, after, depth)
+		return i + 2
+	case in.Op == chip8.OpJP && in.NNN > target && after == target:
+		// an if ending where an else emits its jump would go through that jump, the ROM jumps past it
+		if end := findNatural(blocks, i+2, hi, next, in.NNN); end >= 0 && !(end == hi && d.absorbed[blocks[hi-1].End-2]) {
+			return d.ifBlock(blocks, i+2, end, hi, next, in.NNN, cond, loops, depth)
+		}
+	}
+
+	d.add(depth, "if "+cond.runs+" then "+d.statement(in))
+	d.continueAt(target, after, depth)
+	return i + 2
+}
+
+// findNatural: the index from lo to hi where the code emitted starts with addr, -1 if there's none
+func findNatural(blocks []*Block, lo, hi int, next, addr uint16) int {
+	for j := lo; j <= hi; j++ {
+		if natural(blocks, j, hi, next) == addr {
+			return j
+		}
+	}
+	return -1
+}
+
+// ifBlock: emits the blocks in [lo, end) as the body of an if, which ends at the address
+// endAddr. When the body ends jumping further on, the blocks it jumps over are the else.
+func (d *decompiler) ifBlock(blocks []*Block, lo, end, hi int, next, endAddr uint16, cond condition, loops []loopContext, depth int) int {
+	if last := blocks[end-1].Last(); end < hi && last.Op == chip8.OpJP && last.NNN > endAddr {
+		if elseEnd := findNatural(blocks, end+1, hi, next, last.NNN); elseEnd >= 0 {
+			d.absorbed[blocks[end-1].End-2] = true
+			d.add(depth, "if "+cond.skips+" begin")
+			d.structure(blocks, lo, end, last.NNN, loops, depth+1)
+			d.add(depth, "else")
+			d.structure(blocks, end, elseEnd, last.NNN, loops, depth+1)
+			d.add(depth, "end")
+			return elseEnd
+		}
+	}
+	d.add(depth, "if "+cond.skips+" begin")
+	d.structure(blocks, lo, end, endAddr, loops, depth+1)
+	d.add(depth, "end")
+	return end
+}
+
+func skipCondition(in chip8.Instruction) condition {
+	x, y := register(in.X), register(in.Y)
+	value := fmt.Sprintf("0x%02X", in.KK)
+	switch in.Op {
+	case chip8.OpSEByte:
+		return condition{x + " == " + value, x + " != " + value}
+	case chip8.OpSNEByte:
+		return condition{x + " != " + value, x + " == " + value}
+	case chip8.OpSERegister:
+		return condition{x + " == " + y, x + " != " + y}
+	case chip8.OpSNERegister:
+		return condition{x + " != " + y, x + " == " + y}
+	case chip8.OpSKP:
+		return condition{x + " key", x + " -key"}
+	default:
+		return condition{x + " -key", x + " key"}
+	}
+}
+
+func register(x uint8) string {
+	return fmt.Sprintf("v%x", x)
+}
+
+// statement: the instruction in Octo
+func (d *decompiler) statement(in chip8.Instruction) string {
+	x, y := register(in.X), register(in.Y)
+	value := fmt.Sprintf("0x%02X", in.KK)
+	switch in.Op {
+	case chip8.OpCLS:
+		return "clear"
+	case chip8.OpRET:
+		return "return"
+	case chip8.OpJP:
+		return "jump " + d.ref(in.NNN)
+	case chip8.OpCALL:
+		return d.ref(in.NNN)
+	case chip8.OpLDByte:
+		return x + " := " + value
+	case chip8.OpADDByte:
+		return x + " += " + value
+	case chip8.OpLDRegister:
+		return x + " := " + y
+	case chip8.OpOR:
+		return x + " |= " + y
+	case chip8.OpAND:
+		return x + " &= " + y
+	case chip8.OpXOR:
+		return x + " ^= " + y
+	case chip8.OpADDRegister:
+		return x + " += " + y
+	case chip8.OpSUB:
+		return x + " -= " + y
+	case chip8.OpSHR:
+		return x + " >>= " + y
+	case chip8.OpSUBN:
+		return x + " =- " + y
+	case chip8.OpSHL:
+		return x + " <<= " + y
+	case chip8.OpLDI:
+		return fmt.Sprintf("i := %s%03X", pointerMark, in.NNN)
+	case chip8.OpJPV0:
+		return fmt.Sprintf("jump0 0x%03X", in.NNN)
+	case chip8.OpRND:
+		return x + " := random " + value
+	case chip8.OpDRW:
+		return fmt.Sprintf("sprite %s %s %d", x, y, in.N)
+	case chip8.OpLDVxDT:
+		return x + " := delay"
+	case chip8.OpLDVxK:
+		return x + " := key"
+	case chip8.OpLDDTVx:
+		return "delay := " + x
+	case chip8.OpLDSTVx:
+		return "buzzer := " + x
+	case chip8.OpADDI:
+		return "i += " + x
+	case chip8.OpLDF:
+		return "i := hex " + x
+	case chip8.OpLDB:
+		return "bcd " + x
+	case chip8.OpLDIVx:
+		return "save " + x
+	case chip8.OpLDVxI:
+		return "load " + x
+	case chip8.OpSYS:
+		return fmt.Sprintf("native 0x%03X", in.NNN)
+	}
+	return fmt.Sprintf("0x%02X 0x%02X # not an instruction", in.Opcode>>chip8.ByteSize, in.Opcode&0xFF)
+}
+
+// data: emits the unreachable regions as bytes, a line of 8 bytes each
+func (d *decompiler) data() {
+	const perLine = 8
+	for _, r := range d.regions {
+		d.add(0, "")
+		d.mark(r.Start, 1)
+		if !r.Referenced {
+			d.add(1, "# unreachable, nothing points here")
+		}
+		for addr := r.Start; addr < r.End; addr += perLine {
+			var values []string
+			for a := addr; a < r.End && a < addr+perLine; a++ {
+				values = append(values, fmt.Sprintf("0x%02X", d.g.memory[a]))
+			}
+			d.add(1, strings.Join(values, " "))
+		}
+	}
+}
+
+// resolvePointer: the text with the placeholder of the pointer replaced
+func (d *decompiler) resolvePointer(text string, marked map[uint16]bool) string {
+	i := strings.Index(text, pointerMark)
+	if i < 0 {
+		return text
+	}
+	var addr uint16
+	fmt.Sscanf(text[i+len(pointerMark):i+len(pointerMark)+3], "%03X", &addr)
+	target := fmt.Sprintf("0x%03X", addr)
+	if marked[addr] {
+		target = d.ref(addr)
+	}
+	return text[:i] + target + text[i+len(pointerMark)+3:]
+}
+
+func (d *decompiler) print(w io.Writer) error {
+	marked := map[uint16]bool{}
+	for _, line := range d.lines {
+		if line.label {
+			marked[line.addr] = true
+		}
+	}
+	for i := range d.lines {
+		d.lines[i].text = d.resolvePointer(d.lines[i].text, marked)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, line := range d.lines {
+		switch {
+		case !line.label:
+			if line.text == "" {
+				fmt.Fprintln(bw)
+			} else {
+				fmt.Fprintf(bw, "%s%s\n", strings.Repeat("  ", line.depth), line.text)
+			}
+		case d.used[line.addr] || d.names[line.addr] != "":
+			depth := line.depth - 1
+			if depth < 0 {
+				depth = 0
+			}
+			fmt.Fprintf(bw, "%s: %s\n", strings.Repeat("  ", depth), d.name(line.addr))
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,184 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/franciscocid/chip-8/chip8"
+	"github.com/franciscocid/chip-8/chip8/chip8test"
+	"github.com/franciscocid/chip-8/chip8/octo"
+	"github.com/stretchr/testify/assert"
+)
+
+// runScreen: the screen after running the program with the settings of the ROM for the frames,
+// pressing a few keys along the way
+func runScreen(t *testing.T, rom *chip8.ROM, data []uint8, frames int) string {
+	rand.Seed(1)
+	c := chip8.New()
+	if err := c.LoadROM(&chip8.ROM{Name: rom.Name, Format: rom.Format, Data: data, Info: rom.Info}); err != nil {
+		t.Fatal(err)
+	}
+	ctl := chip8.NewController(c)
+	for frame := 0; frame < frames; frame++ {
+		switch frame {
+		case 100, 200:
+			c.PressKey(0x5)
+		case 110:
+			c.ReleaseKey(0x5)
+			c.PressKey(0x4)
+		case 150:
+			c.ReleaseKey(0x4)
+			c.PressKey(0x6)
+		case 170, 210:
+			c.ReleaseKey(0x6)
+			c.ReleaseKey(0x5)
+		}
+		ctl.StepFrame()
+	}
+	return chip8test.Screen(&c.CurrState)
+}
+
+func decompile(t *testing.T, rom []uint8) string {
+	var b bytes.Buffer
+	assert.NoError(t, Analyze(rom, chip8.ProgramStartAddress).Decompile(&b))
+	return b.String()
+}
+
+func TestDecompile(t *testing.T) {
+	t.Run("Decompile should recover loops, ifs, calls and data", func(t *testing.T) {
+		source := decompile(t, []uint8{
+			0x60, 0x00, // 200 LD V0, 0
+			0xA2, 0x20, // 202 LD I, 0x220
+			0x22, 0x18, // 204 CALL 0x218
+			0x30, 0x05, // 206 SE V0, 5
+			0x12, 0x0E, // 208 JP 0x20E
+			0x71, 0x01, // 20A ADD V1, 1
+			0x12, 0x12, // 20C JP 0x212
+			0x72, 0x01, // 20E ADD V2, 1
+			0xD0, 0x15, // 210 DRW V0, V1, 5
+			0x30, 0x08, // 212 SE V0, 8
+			0x12, 0x04, // 214 JP 0x204
+			0x12, 0x16, // 216 JP 0x216
+			0x70, 0x01, // 218 ADD V0, 1
+			0xE1, 0x9E, // 21A SKP V1
+			0x00, 0xE0, // 21C CLS
+			0x00, 0xEE, // 21E RET
+			0xF0, 0x90, // 220 sprite
+		})
+		assert.Equal(t, `: main
+  v0 := 0x00
+  i := data_220
+  loop
+    sub_218
+    if v0 == 0x05 begin
+      v1 += 0x01
+    else
+      v2 += 0x01
+      sprite v0 v1 5
+    end
+  if v0 != 0x08 then again
+  loop
+  again
+
+: sub_218
+  v0 += 0x01
+  if v1 -key then clear
+  return
+
+: data_220
+  0xF0 0x90
+`, source)
+	})
+
+	t.Run("Decompile should keep jumps to labels for what isn't structured", func(t *testing.T) {
+		source := decompile(t, []uint8{
+			0x30, 0x00, // 200 SE V0, 0
+			0x31, 0x01, // 202 SE V1, 1
+			0x60, 0x05, // 204 LD V0, 5
+			0x12, 0x06, // 206 JP 0x206
+		})
+		assert.Equal(t, `: main
+  if v0 == 0x00 then jump label_204
+  if v1 == 0x01 then jump label_206
+: label_204
+  v0 := 0x05
+  loop
+  : label_206
+  again
+`, source)
+	})
+
+	t.Run("Decompile should keep every skip and jump of the ROM", func(t *testing.T) {
+		rom := []uint8{
+			0x60, 0x00, // 200 LD V0, 0
+			0x70, 0x01, // 202 ADD V0, 1
+			0x40, 0x08, // 204 SNE V0, 8
+			0x12, 0x0C, // 206 JP 0x20C
+			0x12, 0x0A, // 208 JP 0x20A
+			0x12, 0x02, // 20A JP 0x202
+			0x12, 0x0C, // 20C JP 0x20C
+		}
+		source := decompile(t, rom)
+		assert.Equal(t, `: main
+  v0 := 0x00
+  loop
+    v0 += 0x01
+    while v0 != 0x08
+    jump label_20A
+  : label_20A
+  again
+  loop
+  again
+`, source)
+		assembled, err := octo.Assemble(source)
+		assert.NoError(t, err)
+		assert.Equal(t, rom, assembled)
+	})
+
+	// subroutines that never return can have their entry inside a loop of the code they go on to
+	t.Run("Decompile should name every subroutine of the bundled ROMs", func(t *testing.T) {
+		paths, err := filepath.Glob(filepath.Join("..", "..", "roms", "*.ch8"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range paths {
+			rom, err := chip8.LoadROMFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := Analyze(rom.Data, chip8.ProgramStartAddress)
+			var b bytes.Buffer
+			assert.NoError(t, g.Decompile(&b), path)
+			source := b.String()
+			assert.True(t, strings.HasPrefix(source, ": main\n"), path)
+			for _, s := range g.Subroutines {
+				assert.Contains(t, source, fmt.Sprintf(": sub_%03X\n", s.Entry), path)
+			}
+			assert.NotContains(t, source, "not an instruction", path)
+		}
+	})
+
+	t.Run("Decompile should give source that Octo assembles into a program that runs like the ROM", func(t *testing.T) {
+		paths, err := filepath.Glob(filepath.Join("..", "..", "roms", "*.ch8"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range paths {
+			rom, err := chip8.LoadROMFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assembled, err := octo.Assemble(decompile(t, rom.Data))
+			if !assert.NoError(t, err, path) {
+				continue
+			}
+			for _, frames := range []int{120, 300} {
+				assert.Equal(t, runScreen(t, rom, rom.Data, frames), runScreen(t, rom, assembled, frames), "%s after %d frames", path, frames)
+			}
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+// dotEdgeStyles: the attributes of the edges of every kind
+var dotEdgeStyles = [...]string{
+	Fallthrough: ``,
+	Jump:        ` [label="jump"]`,
+	Skip:        ` [label="skip" color="blue"]`,
+	Call:        ` [label="call" style="dashed"]`,
+	Return:      ` [label="return" style="dotted"]`,
+}
+
+// WriteDOT: writes the graph in the Graphviz DOT language, a box per block with its
+// disassembly. Subroutine entries have a double border, blocks ending on computed jumps are
+// red and blocks with self-modifying writes are orange.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph cfg {")
+	fmt.Fprintln(bw, `	node [shape=box fontname="monospace"];`)
+
+	selfModifying := map[uint16]bool{}
+	for _, write := range g.SelfModifying() {
+		selfModifying[write.PC] = true
+	}
+
+	for _, b := range g.Blocks {
+		var label strings.Builder
+		fmt.Fprintf(&label, "%s:\\l", g.blockName(b.Start))
+		attrs := ""
+		for i, in := range b.Instructions {
+			addr := b.Address(i)
+			fmt.Fprintf(&label, "%03X  %s\\l", addr, chip8.Disassemble(in.Opcode))
+			if selfModifying[addr] {
+				attrs = ` color="orange"`
+			}
+		}
+		if b.Last().Op == chip8.OpJPV0 {
+			attrs = ` color="red"`
+		}
+		if b.Start == g.Start || g.subroutines[b.Start] != nil {
+			attrs += ` peripheries=2`
+		}
+		fmt.Fprintf(bw, "\tb%03X [label=\"%s\"%s];\n", b.Start, label.String(), attrs)
+	}
+
+	for _, b := range g.Blocks {
+		for _, edge := range b.Successors {
+			fmt.Fprintf(bw, "\tb%03X -> b%03X%s;\n", edge.From, edge.To, dotEdgeStyles[edge.Kind])
+		}
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// blockName: the name of the block on the graph, the entries are named after what they start
+func (g *Graph) blockName(addr uint16) string {
+	switch {
+	case addr == g.Start:
+		return "main"
+	case g.subroutines[addr] != nil:
+		return fmt.Sprintf("sub_%03X", addr)
+	}
+	return fmt.Sprintf("block_%03X", addr)
+}
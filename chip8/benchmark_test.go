@@ -0,0 +1,164 @@
+package chip8
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// opcodeFamilies: an opcode of each kind of instruction, run by BenchmarkExecuteOpcodeFamilies.
+// resets: the instruction moves PC, SP or I so running it over and over would end in a fault.
+var opcodeFamilies = []struct {
+	name   string
+	opcode uint16
+	resets bool
+}{
+	{"CLS", 0x00E0, false},
+	{"JP", 0x1300, false},
+	{"CALL", 0x2300, true},
+	{"SE", 0x3001, true},
+	{"LD Vx byte", 0x6012, false},
+	{"ADD Vx byte", 0x7012, false},
+	{"ALU", 0x8014, false},
+	{"SHR", 0x8016, false},
+	{"LD I", 0xA300, false},
+	{"RND", 0xC0FF, false},
+	{"DRW", 0xD015, false},
+	{"SKP", 0xE09E, true},
+	{"LD B", 0xF033, false},
+	{"LD [I]", 0xFF55, true},
+	{"LD Vx [I]", 0xFF65, true},
+}
+
+// BenchmarkExecuteOpcodeFamilies: times the execution of a decoded instruction of each family,
+// without the decoding and the copy of the machine BenchmarkExecuteOpcode includes. The families
+// that move PC, SP or I put them back inline, three stores timed along with the instruction,
+// since stopping the timer on every run costs far more than the instructions themselves.
+func BenchmarkExecuteOpcodeFamilies(b *testing.B) {
+	for _, family := range opcodeFamilies {
+		b.Run(family.name, func(b *testing.B) {
+			c := New()
+			c.CurrState.I = 0x300
+			pc, sp, i := c.CurrState.PC, c.CurrState.SP, c.CurrState.I
+			in := Decode(family.opcode)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				if family.resets {
+					c.CurrState.PC, c.CurrState.SP, c.CurrState.I = pc, sp, i
+				}
+				c.execute(in)
+			}
+			if c.Fault != nil {
+				b.Fatal(c.Fault)
+			}
+		})
+	}
+}
+
+func BenchmarkDrawSprite(b *testing.B) {
+	cases := []struct {
+		name   string
+		quirks Quirks
+		x, y   uint8
+	}{
+		{"aligned", Quirks{}, 8, 4},
+		{"unaligned", Quirks{}, 13, 4},
+		{"clipped", Quirks{}, 60, 28},
+		{"wrapped", Quirks{Wrap: true}, 60, 28},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			c := New()
+			c.Quirks = tc.quirks
+			c.CurrState.I = FontsStartAddress
+			c.CurrState.V[0x0], c.CurrState.V[0x1] = tc.x, tc.y
+			in := Decode(0xD01F)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.drawSprite(in)
+			}
+		})
+	}
+}
+
+// BenchmarkROMFrames: runs the bundled ROMs headless a 60Hz frame at a time, with their database settings
+func BenchmarkROMFrames(b *testing.B) {
+	paths, err := filepath.Glob("../roms/*.ch8")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, path := range paths {
+		rom, err := LoadROMFile(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(rom.Name, func(b *testing.B) {
+			c := New()
+			if err := c.LoadROM(rom); err != nil {
+				b.Fatal(err)
+			}
+			ctl := NewController(c)
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				ctl.StepFrame()
+			}
+			b.ReportMetric(float64(c.Executed)/time.Since(start).Seconds(), "instructions/s")
+			b.ReportMetric(float64(c.TickCount-c.Executed)/float64(b.N), "idle-ticks/frame")
+		})
+	}
+}
+
+// BenchmarkRun: the benchmark program on each backend, Run a frame of ticks at a time
+func BenchmarkRun(b *testing.B) {
+	for _, backend := range []Backend{BackendInterpreter, BackendJIT} {
+		b.Run(backend.String(), func(b *testing.B) {
+			c := New()
+			c.Backend = backend
+			if err := c.LoadGame(benchmarkROM); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			for ran := 0; ran < b.N; ran += DefaultTickRate {
+				c.Run(DefaultTickRate)
+			}
+			b.ReportMetric(float64(c.Executed)/time.Since(start).Seconds(), "instructions/s")
+			b.ReportMetric(float64(c.TickCount-c.Executed)/float64(b.N), "idle-ticks/op")
+		})
+	}
+}
+
+func BenchmarkHistory(b *testing.B) {
+	for _, limit := range []int{0, 1, 64, 1024} {
+		b.Run(fmt.Sprintf("limit %d", limit), func(b *testing.B) {
+			c := New()
+			c.HistoryLimit = limit
+			if err := c.LoadGame(benchmarkROM); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Tick(0)
+			}
+		})
+	}
+}
+
+// BenchmarkSaveState: saving and restoring a whole state, which is how history and rewinding keep them
+func BenchmarkSaveState(b *testing.B) {
+	c := New()
+	if err := c.LoadGame(benchmarkROM); err != nil {
+		b.Fatal(err)
+	}
+	var saved State
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		saved = c.CurrState
+		c.CurrState = saved
+	}
+}
@@ -1,11 +1,65 @@
 package chip8
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 type Chip8 struct {
-	CurrState    State
-	StateHistory []State
-	TickCount    int64
+	CurrState State
+	TickCount int64
+	// Executed counts the instructions run, unlike TickCount it leaves out the ticks idled
+	// by a Dxyn waiting for the vertical blank
+	Executed int64
+	Hooks    Hooks
+	// HistoryLimit is how many of the last states Tick keeps for History, none when it's 0
+	HistoryLimit int
+	// Log gets a line with every instruction run by Tick, nothing is logged when it's nil
+	Log io.Writer
+
+	Font        FontSet
+	FontAddress uint16
+
+	Platform    Platform
+	AddressMode AddressMode
+
+	Quirks Quirks
+	// TickRate is how many instructions run on every 60Hz frame, the timers count down once per frame
+	TickRate int
+	// ROM is the last ROM loaded by LoadROM
+	ROM *ROM
+	// Timing decides how long each instruction takes, see TimingMode
+	Timing TimingMode
+	// Cycles counts the machine cycles run with COSMAC VIP timing since the game was loaded
+	Cycles int64
+
+	// Fault is set when the machine stops because of an invalid access, Tick does nothing while it's set
+	Fault error
+	// Backend decides how Run executes the instructions, see Backend
+	Backend Backend
+
+	instructionPC uint16
+	cache         *instructionCache
+	jit           *jitCache
+	// history is a ring of the last HistoryLimit states, historyNext is where the next one goes
+	history     []State
+	historyNext int
+	// vblank is set by EndFrame when a Dxyn is waiting, and lets it draw
+	vblank           bool
+	waitingForVBlank bool
+}
+
+// Hooks are called as the emulation runs so tools can observe it, any of them can be nil
+type Hooks struct {
+	// SpriteDrawn is called by Dxyn with the address and height of the sprite
+	SpriteDrawn func(addr uint16, height uint8)
+	// MemoryRead is called on every memory read, after the address mode is applied
+	MemoryRead func(addr uint16, value uint8, access Access)
+	// MemoryWrite is called on every memory write, after the address mode is applied
+	MemoryWrite func(addr uint16, value uint8)
+	// InstructionFetched is called by Tick before running every instruction, while the
+	// current state is still the one the instruction starts from
+	InstructionFetched func(pc uint16, in Instruction)
 }
 
 const (
@@ -22,62 +76,152 @@ const (
 	FirstScreenBitMask uint64 = 0x8000000000000000
 )
 
-func (c *Chip8) LoadGame(gameData []uint8) {
-	c.StateHistory = make([]State, 0)
+// LoadGame: resets the machine and loads the game at the load address of the platform,
+// along with the fonts. The game must fit in the memory of the platform, and the fonts
+// below it; the machine is left alone when they don't.
+func (c *Chip8) LoadGame(gameData []uint8) error {
+	if c.Platform.MemorySize == 0 {
+		c.Platform = PlatformCHIP8
+	}
+	if err := checkGame(gameData, c.Platform); err != nil {
+		return err
+	}
+	if len(c.Font.Small) != 0 {
+		if err := checkFont(c.Font, c.FontAddress, c.Platform); err != nil {
+			return err
+		}
+	}
+
+	c.history, c.historyNext = c.history[:0], 0
+	c.Fault = nil
+	c.Cycles = 0
+	c.vblank, c.waitingForVBlank = false, false
 
 	c.CurrState = State{
-		PC: ProgramStartAddress,
+		PC: c.Platform.LoadAddress,
+	}
+	copy(c.CurrState.Memory[c.Platform.LoadAddress:], gameData)
+	c.LoadFonts()
+	c.InvalidateInstructionCache()
+	return nil
+}
+
+// checkGame: an error if the game is empty or doesn't fit in the memory of the platform
+func checkGame(gameData []uint8, platform Platform) error {
+	if len(gameData) == 0 {
+		return ErrEmptyROM
 	}
-	for i, data := range gameData {
-		c.CurrState.Memory[int(ProgramStartAddress)+i] = data
+	if capacity := platform.Capacity(); len(gameData) > capacity {
+		return fmt.Errorf("%w: it has %d bytes but %s programs can have at most %d bytes starting at 0x%03x",
+			ErrROMTooLarge, len(gameData), platform.Name, capacity, platform.LoadAddress)
 	}
+	return nil
 }
 
-func (c8 *Chip8) LoadFonts() {
-	fonts := []uint8{
-		0xF0, 0x90, 0x90, 0x90, 0xF0, // 0
-		0x20, 0x60, 0x20, 0x20, 0x70, // 1
-		0xF0, 0x10, 0xF0, 0x80, 0xF0, // 2
-		0xF0, 0x10, 0xF0, 0x10, 0xF0, // 3
-		0x90, 0x90, 0xF0, 0x10, 0x10, // 4
-		0xF0, 0x80, 0xF0, 0x10, 0xF0, // 5
-		0xF0, 0x80, 0xF0, 0x90, 0xF0, // 6
-		0xF0, 0x10, 0x20, 0x40, 0x40, // 7
-		0xF0, 0x90, 0xF0, 0x90, 0xF0, // 8
-		0xF0, 0x90, 0xF0, 0x10, 0xF0, // 9
-		0xF0, 0x90, 0xF0, 0x90, 0x90, // A
-		0xE0, 0x90, 0xE0, 0x90, 0xE0, // B
-		0xF0, 0x80, 0x80, 0x80, 0xF0, // C
-		0xE0, 0x90, 0x90, 0x90, 0xE0, // D
-		0xF0, 0x80, 0xF0, 0x80, 0xF0, // E
-		0xF0, 0x80, 0xF0, 0x80, 0x80, // F
-	}
-	for addr, value := range fonts {
-		c8.CurrState.Memory[int(FontsStartAddress)+addr] = value
+// LoadFonts: writes the selected font into memory at the font address
+func (c *Chip8) LoadFonts() {
+	if len(c.Font.Small) == 0 {
+		c.Font, c.FontAddress = DefaultFont, FontsStartAddress
+	}
+	for addr, value := range c.Font.Small {
+		c.CurrState.Memory[int(c.FontAddress)+addr] = value
+	}
+	for addr, value := range c.Font.Big {
+		c.CurrState.Memory[int(c.BigFontAddress())+addr] = value
 	}
+	c.InvalidateInstructionCache()
 }
 
 func (c *Chip8) Tick(deltaTime float64) {
-	fmt.Printf("PC %03x\t", c.CurrState.PC)
-	opcode := c.CurrState.Opcode()
-	c.CurrState.PC += 2
+	if c.Fault != nil {
+		return
+	}
 
-	newState := c.ExecuteOpcode(opcode)
+	pc := c.CurrState.PC
+	c.instructionPC = pc
+	in := c.fetchInstruction()
+	if c.waitForVBlank(in.Opcode) {
+		// The machine idles until EndFrame, the timers keep their pace meanwhile
+		c.countDownFixedTimers(&c.CurrState)
+		c.TickCount++
+		return
+	}
+	if c.Hooks.InstructionFetched != nil {
+		c.Hooks.InstructionFetched(pc, in)
+	}
+	if c.Log != nil {
+		fmt.Fprintf(c.Log, "PC %03x\tOP %04x\t%s\n", pc, in.Opcode, Disassemble(in.Opcode))
+	}
+	if c.HistoryLimit > 0 {
+		c.remember(c.CurrState)
+	}
+	c.CurrState.PC += OpcodeSize
 
-	if c.TickCount%8 == 0 {
-		if newState.DelayTimer > 0 {
-			newState.DelayTimer--
-		}
-		if newState.SoundTimer > 0 {
-			newState.SoundTimer--
-		}
+	if c.Timing == TimingCOSMACVIP {
+		c.Cycles += int64(c.vipCycles(in.Opcode))
+	}
+	c.execute(in)
+	if int(c.CurrState.PC) >= MemorySize {
+		// Skips, Bnnn and the last opcode of memory leave PC past the end, it goes
+		// through the address mode like any other access
+		c.CurrState.PC, _ = c.resolve(c.CurrState.PC)
 	}
 
-	c.StateHistory = append(c.StateHistory, c.CurrState)
-	c.CurrState = newState
+	c.Executed++
+	c.countDownFixedTimers(&c.CurrState)
 	c.TickCount++
 }
 
+// remember: adds the state to the history, dropping the oldest one past HistoryLimit
+func (c *Chip8) remember(s State) {
+	if len(c.history) > c.HistoryLimit || (c.historyNext != 0 && len(c.history) != c.HistoryLimit) {
+		// The limit changed, the ring starts over from the oldest state kept
+		history := c.History()
+		if len(history) > c.HistoryLimit {
+			history = history[len(history)-c.HistoryLimit:]
+		}
+		c.history, c.historyNext = history, 0
+	}
+	if len(c.history) < c.HistoryLimit {
+		c.history = append(c.history, s)
+		return
+	}
+	c.history[c.historyNext] = s
+	c.historyNext = (c.historyNext + 1) % len(c.history)
+}
+
+// History: the states before the last instructions run by Tick, oldest first
+func (c *Chip8) History() []State {
+	history := make([]State, 0, len(c.history))
+	history = append(history, c.history[c.historyNext:]...)
+	return append(history, c.history[:c.historyNext]...)
+}
+
+// countDownTimers: the 60Hz count down of the delay and sound timers
+func (c *Chip8) countDownTimers(s *State) {
+	if s.DelayTimer > 0 {
+		s.DelayTimer--
+	}
+	if s.SoundTimer > 0 {
+		s.SoundTimer--
+	}
+}
+
+// countDownFixedTimers: with fixed timing the timers count down once every TickRate ticks
+func (c *Chip8) countDownFixedTimers(s *State) {
+	if c.Timing == TimingFixed && c.TickCount%int64(c.tickRate()) == 0 {
+		c.countDownTimers(s)
+	}
+}
+
+// tickRate: TickRate, or DefaultTickRate when it isn't set
+func (c *Chip8) tickRate() int {
+	if c.TickRate <= 0 {
+		return DefaultTickRate
+	}
+	return c.TickRate
+}
+
 func (c *Chip8) PressKey(key uint8) {
 	c.CurrState.Keyboard = [16]bool{}
 	c.CurrState.Keyboard[key] = true
@@ -87,108 +231,28 @@ func (c *Chip8) ReleaseKey(key uint8) {
 	c.CurrState.Keyboard[key] = false
 }
 
+// ExecuteOpcode: returns the state after running the opcode on the current state,
+// which is left as it was along with the rest of the machine. The opcode runs on a
+// copy without the hooks and the caches, so no hook is called and a fault it
+// causes is dropped.
 func (c *Chip8) ExecuteOpcode(opcode uint16) State {
-	fmt.Printf("OP %04x\t", opcode)
-	defer fmt.Println()
-
-	switch opcode {
-	case 0x00E0:
-		return c.clearScreen()
-	case 0x00EE:
-		return c.returnFromSubroutine()
-	}
-
-	addr := opcode & 0x0FFF
-	x := uint8(opcode & 0x0F00 >> ByteSize)
-	y := uint8(opcode & 0x00F0 >> NibbleSize)
-	value := uint8(opcode & 0x00FF)
-	nibble := uint8(opcode & 0x000F)
-
-	firstOpcodeByte := opcode >> (NibbleSize * 3)
-	switch firstOpcodeByte {
-	case 0x0:
-		return c.syscall(addr)
-	case 0x1:
-		return c.jumpToAddress(addr)
-	case 0x2:
-		return c.callSubroutine(addr)
-	case 0x3:
-		return c.skipIfVxEqualValue(x, value)
-	case 0x4:
-		return c.skipIfVxNotEqualValue(x, value)
-	case 0x5:
-		return c.skipIfVxEqualVy(x, y)
-	case 0x6:
-		return c.loadIntoVx(x, value)
-	case 0x7:
-		return c.addToVx(x, value)
-	case 0x8:
-		switch opcode & 0x000F {
-		case 0x0:
-			return c.loadVxIntoVy(x, y)
-		case 0x1:
-			return c.loadBitwiseVxOrVyIntoVx(x, y)
-		case 0x2:
-			return c.loadBitwiseVxAndVyIntoVx(x, y)
-		case 0x3:
-			return c.loadBitwiseVxExclusiveOrVyIntoVx(x, y)
-		case 0x4:
-			return c.addVyToVx(x, y)
-		case 0x5:
-			return c.subtractVxByVy(x, y)
-		case 0x6:
-			return c.shiftVxRight(x)
-		case 0x7:
-			return c.loadVySubtractedByVxIntoVx(x, y)
-		case 0xE:
-			return c.shiftVxLeft(x)
-		}
-	case 0x9:
-		return c.skipIfVxNotEqualVy(x, y)
-	case 0xA:
-		return c.loadAddressIntoI(addr)
-	case 0xB:
-		return c.jumpToAddressPlusV0(addr)
-	case 0xC:
-		return c.loadRandomValueBitwiseAndValueIntoVx(x, value)
-	case 0xD:
-		return c.drawSprite(x, y, nibble)
-	case 0xE:
-		switch opcode & 0x00FF {
-		case 0x9E:
-			return c.skipIfVxKeyIsPressed(x)
-		case 0xA1:
-			return c.skipIfVxKeyIsNotPressed(x)
-		}
-	case 0xF:
-		switch opcode & 0x00FF {
-		case 0x07:
-			return c.loadDelayTimerIntoVx(x)
-		case 0x0A:
-			return c.waitButtonPressAndLoadIntoVx(x)
-		case 0x15:
-			return c.loadVxIntoDelayTimer(x)
-		case 0x18:
-			return c.loadVxIntoSoundTimer(x)
-		case 0x1E:
-			return c.addVxToI(x)
-		case 0x29:
-			return c.loadVxDigitSpriteAddressIntoI(x)
-		case 0x33:
-			return c.loadVxDigitsIntoI(x)
-		case 0x55:
-			return c.loadRangeV0ToVxIntoMemoryStartingFromI(x)
-		case 0x65:
-			return c.loadMemoryStartingFromIIntoRangeV0ToVx(x)
-		}
-	}
-	return c.CurrState
+	scratch := *c
+	scratch.Hooks = Hooks{}
+	scratch.Log = nil
+	scratch.cache, scratch.jit = nil, nil
+	scratch.execute(Decode(opcode))
+	return scratch.CurrState
 }
 
 func New() *Chip8 {
 	return &Chip8{
-		CurrState:    State{},
-		StateHistory: []State{},
-		TickCount:    0,
+		CurrState:   State{},
+		TickCount:   0,
+		Font:        DefaultFont,
+		FontAddress: FontsStartAddress,
+		Platform:    PlatformCHIP8,
+		Quirks:      DefaultQuirks,
+		TickRate:    DefaultTickRate,
+		cache:       &instructionCache{},
 	}
 }
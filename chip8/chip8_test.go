@@ -194,7 +194,7 @@ func TestChip8(t *testing.T) {
 		c.CurrState.V[0xE] = 0x01
 		newState := c.ExecuteOpcode(0x83E5)
 		assert.Equal(t, uint8(0x00), newState.V[0x3], "Vx should have the value of Vx - Vy")
-		assert.NotEqual(t, uint8(0x01), newState.V[0xF], "VF should have the value 1, since NO borrow was made")
+		assert.Equal(t, uint8(0x01), newState.V[0xF], "VF should have the value 1, since NO borrow was made")
 	})
 
 	t.Run("(SUB Vx, Vy) Instruction 8xy5 should subtract the Vy value into the current Vx value and VF should be set to 1 when there was NO borrow", func(t *testing.T) {
@@ -208,10 +208,10 @@ func TestChip8(t *testing.T) {
 
 	t.Run("(SHR Vx {, Vy}) Instruction 8xy6 should shift right the bits on Vx", func(t *testing.T) {
 		c := New()
-		c.CurrState.V[0x0] = 0b00010001
+		c.CurrState.V[0x0] = 0b00010000
 		newState := c.ExecuteOpcode(0x8016)
 		assert.Equal(t, uint8(0b00001000), newState.V[0x0], "Vx bits should be shifted right once")
-		assert.Equal(t, uint8(0x00), newState.V[0xF], "VF should be set to 0, since least significant byte is 0")
+		assert.Equal(t, uint8(0x00), newState.V[0xF], "VF should be set to 0, since least significant bit is 0")
 	})
 
 	t.Run("(SHR Vx {, Vy}) Instruction 8xy6 should shift right the bits on Vx and VF should be set to 1 if least significant bit is 1", func(t *testing.T) {
@@ -250,12 +250,23 @@ func TestChip8(t *testing.T) {
 
 	t.Run("(SHL Vx {, Vy}) Instruction 8xyE should shift left the bits on Vx and VF should be set to 1 if most significant bit is 1", func(t *testing.T) {
 		c := New()
-		c.CurrState.V[0x0] = 0b01010011
+		c.CurrState.V[0x0] = 0b11010011
 		newState := c.ExecuteOpcode(0x801E)
 		assert.Equal(t, uint8(0b10100110), newState.V[0x0], "Vx bits should be shifted left once")
 		assert.Equal(t, uint8(0x01), newState.V[0xF], "VF should be set to 1, since most significant bit is 1")
 	})
 
+	t.Run("8xy5, 8xy7, 8xy6 and 8xyE should leave the flag in VF when Vx is VF", func(t *testing.T) {
+		c := New()
+		c.Quirks = Quirks{Shift: true}
+		c.CurrState.V[0xF] = 0x05
+		c.CurrState.V[0x1] = 0x03
+		assert.Equal(t, uint8(0x01), c.ExecuteOpcode(0x8F15).V[0xF], "8Fy5 should leave NOT borrow")
+		assert.Equal(t, uint8(0x00), c.ExecuteOpcode(0x8F17).V[0xF], "8Fy7 should leave NOT borrow")
+		assert.Equal(t, uint8(0x01), c.ExecuteOpcode(0x8F16).V[0xF], "8Fy6 should leave the bit shifted out")
+		assert.Equal(t, uint8(0x00), c.ExecuteOpcode(0x8F1E).V[0xF], "8FyE should leave the bit shifted out")
+	})
+
 	t.Run("(SNE Vx, Vy) Instruction 9xkk skips next instruction if Vx is NOT equals Vy", func(t *testing.T) {
 		c := New()
 		c.CurrState.PC = 0x200
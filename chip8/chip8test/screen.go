@@ -0,0 +1,145 @@
+// Package chip8test reads the chip-8 screen back on tests, so they can assert on what a
+// program shows instead of on the bits of State.Graphics.
+//
+// Tests in the chip8 package itself can't import it; they use an external chip8_test package.
+package chip8test
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+const (
+	// On and Off are the characters of the pixels on the text screens
+	On  = '#'
+	Off = '.'
+)
+
+// Bounds: the whole screen
+var Bounds = image.Rect(0, 0, chip8.ScreenWidth, chip8.ScreenHeight)
+
+// Screen: the whole screen as text, a line per row
+func Screen(s *chip8.State) string {
+	return Region(s, Bounds)
+}
+
+// Region: the pixels inside r as text, a line per row. Pixels out of the screen are off.
+func Region(s *chip8.State, r image.Rectangle) string {
+	var b strings.Builder
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if pixel(s, x, y) {
+				b.WriteByte(On)
+			} else {
+				b.WriteByte(Off)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// MatchPattern: true if the pattern, written like Region does, is on the screen with its top
+// left corner at x, y. Spaces around the lines are ignored, so patterns can be indented.
+func MatchPattern(s *chip8.State, x, y int, pattern string) bool {
+	lines := strings.Split(strings.TrimSpace(pattern), "\n")
+	width := 0
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+		if len(lines[i]) > width {
+			width = len(lines[i])
+		}
+	}
+	for i, line := range lines {
+		lines[i] = line + strings.Repeat(string(Off), width-len(line))
+	}
+	want := strings.Join(lines, "\n") + "\n"
+	return Region(s, image.Rect(x, y, x+width, y+len(lines))) == want
+}
+
+// MatchSprite: true if the 8 pixels wide rows of the sprite are on the screen with their top
+// left corner at x, y, off pixels included
+func MatchSprite(s *chip8.State, x, y int, sprite []uint8) bool {
+	return matchGlyph(s, x, y, sprite, chip8.ByteSize)
+}
+
+// ReadHex: finds the hexadecimal digits of the font loaded on the machine inside r, left to
+// right, like the scores games draw with Fx29. Anything that isn't a digit is skipped.
+func ReadHex(c8 *chip8.Chip8, r image.Rectangle) string {
+	glyphs := fontGlyphs(c8)
+	width := glyphsWidth(glyphs)
+	r = r.Intersect(Bounds)
+
+	var b strings.Builder
+	for x := r.Min.X; x < r.Max.X; x++ {
+		digit := findDigit(&c8.CurrState, glyphs, width, x, r)
+		if digit < 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%X", digit)
+		x += width - 1
+	}
+	return b.String()
+}
+
+// findDigit: the digit drawn on the column x at any row of r, -1 if there's none
+func findDigit(s *chip8.State, glyphs [][]uint8, width, x int, r image.Rectangle) int {
+	for y := r.Min.Y; y+len(glyphs[0]) <= r.Max.Y; y++ {
+		for digit, glyph := range glyphs {
+			if matchGlyph(s, x, y, glyph, width) {
+				return digit
+			}
+		}
+	}
+	return -1
+}
+
+// fontGlyphs: the 16 glyphs of the small font, read from the memory where LoadFonts wrote them
+func fontGlyphs(c8 *chip8.Chip8) [][]uint8 {
+	const height = chip8.SmallFontSize / 0x10
+	glyphs := make([][]uint8, 0x10)
+	for digit := range glyphs {
+		addr := int(c8.FontAddress) + digit*height
+		glyphs[digit] = c8.CurrState.Memory[addr : addr+height]
+	}
+	return glyphs
+}
+
+// glyphsWidth: how many columns the widest glyph uses
+func glyphsWidth(glyphs [][]uint8) int {
+	width := 0
+	for _, glyph := range glyphs {
+		for _, row := range glyph {
+			for col := width; col < chip8.ByteSize; col++ {
+				if row&(chip8.FirstFontBitMask>>col) != 0 {
+					width = col + 1
+				}
+			}
+		}
+	}
+	return width
+}
+
+// matchGlyph: true if the leftmost width pixels of every row of the glyph match the screen at x, y
+func matchGlyph(s *chip8.State, x, y int, glyph []uint8, width int) bool {
+	for row, value := range glyph {
+		for col := 0; col < width; col++ {
+			want := value&(chip8.FirstFontBitMask>>col) != 0
+			if pixel(s, x+col, y+row) != want {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// pixel: the pixel at x, y, off when it's out of the screen
+func pixel(s *chip8.State, x, y int) bool {
+	if x < 0 || y < 0 || x >= chip8.ScreenWidth || y >= chip8.ScreenHeight {
+		return false
+	}
+	return s.GetPixel(uint8(x), uint8(y))
+}
@@ -0,0 +1,89 @@
+package chip8test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/franciscocid/chip-8/chip8"
+	"github.com/stretchr/testify/assert"
+)
+
+// scoreROM: draws the decimal digits of 12 with Fx33 and Fx29, the way games draw scores
+var scoreROM = []uint8{
+	0x63, 0x0C, // 200: LD V3, 12
+	0xA3, 0x00, // 202: LD I, 0x300
+	0xF3, 0x33, // 204: LD B, V3
+	0xF2, 0x65, // 206: LD V2, [I]
+	0x64, 0x08, // 208: LD V4, 8
+	0x65, 0x03, // 20A: LD V5, 3
+	0xF0, 0x29, // 20C: LD F, V0
+	0xD4, 0x55, // 20E: DRW V4, V5, 5
+	0x74, 0x05, // 210: ADD V4, 5
+	0xF1, 0x29, // 212: LD F, V1
+	0xD4, 0x55, // 214: DRW V4, V5, 5
+	0x74, 0x05, // 216: ADD V4, 5
+	0xF2, 0x29, // 218: LD F, V2
+	0xD4, 0x55, // 21A: DRW V4, V5, 5
+	0x12, 0x1C, // 21C: JP 0x21C
+}
+
+func runScoreROM(t *testing.T) *chip8.Chip8 {
+	c := chip8.New()
+	if err := c.LoadGame(scoreROM); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		c.Tick(0)
+	}
+	return c
+}
+
+func TestScreen(t *testing.T) {
+	t.Run("ReadHex should read the digits drawn with the font", func(t *testing.T) {
+		c := runScoreROM(t)
+		assert.Equal(t, "012", ReadHex(c, Bounds))
+		assert.Equal(t, "12", ReadHex(c, image.Rect(12, 0, 30, 10)))
+		assert.Equal(t, "", ReadHex(c, image.Rect(0, 10, 64, 32)))
+	})
+
+	t.Run("ReadHex should use the font loaded on the machine", func(t *testing.T) {
+		c := chip8.New()
+		assert.NoError(t, c.SelectFont(chip8.FontSets["dream6800"], chip8.FontsStartAddress))
+		assert.NoError(t, c.LoadGame(scoreROM))
+		for i := 0; i < 20; i++ {
+			c.Tick(0)
+		}
+		assert.Equal(t, "012", ReadHex(c, Bounds))
+	})
+
+	t.Run("Region should render the pixels as text", func(t *testing.T) {
+		c := runScoreROM(t)
+		assert.Equal(t, ""+
+			"..#.\n"+
+			".##.\n"+
+			"..#.\n"+
+			"..#.\n"+
+			".###\n", Region(&c.CurrState, image.Rect(13, 3, 17, 8)))
+		assert.Len(t, Screen(&c.CurrState), (chip8.ScreenWidth+1)*chip8.ScreenHeight)
+	})
+
+	t.Run("MatchPattern should compare a region with a pattern", func(t *testing.T) {
+		c := runScoreROM(t)
+		one := `
+			..#.
+			.##.
+			..#.
+			..#.
+			.###
+		`
+		assert.True(t, MatchPattern(&c.CurrState, 13, 3, one))
+		assert.False(t, MatchPattern(&c.CurrState, 8, 3, one))
+	})
+
+	t.Run("MatchSprite should compare the 8 columns of the sprite", func(t *testing.T) {
+		c := runScoreROM(t)
+		two := chip8.DefaultFont.Small[2*5 : 3*5]
+		assert.True(t, MatchSprite(&c.CurrState, 18, 3, two))
+		assert.False(t, MatchSprite(&c.CurrState, 13, 3, two))
+	})
+}
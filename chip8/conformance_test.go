@@ -0,0 +1,176 @@
+package chip8
+
+import (
+	"flag"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden framebuffers of the ROM conformance tests")
+
+// keyInput: a key pressed or released before the frame runs
+type keyInput struct {
+	frame int
+	key   uint8
+	press bool
+}
+
+// hold: presses the key for the frames in [from, to)
+func hold(key uint8, from, to int) []keyInput {
+	return []keyInput{{from, key, true}, {to, key, false}}
+}
+
+// conformanceROM: a bundled ROM run headless for a number of frames with scripted input.
+// The framebuffer left on screen is compared with testdata/golden/<rom>.txt.
+type conformanceROM struct {
+	name   string
+	frames int
+	input  []keyInput
+}
+
+var conformanceROMs = []conformanceROM{
+	{name: "ibm.ch8", frames: 60},
+	{name: "test_opcode.ch8", frames: 120},
+	{name: "maze.ch8", frames: 300},
+	{name: "random_number_test.ch8", frames: 120, input: hold(0x0, 60, 70)},
+	{name: "pong.ch8", frames: 300, input: append(hold(0x1, 30, 90), hold(0xC, 120, 200)...)},
+	{name: "tetris.ch8", frames: 400, input: append(hold(0x5, 60, 64), hold(0x6, 100, 130)...)},
+	{name: "invaders.ch8", frames: 400, input: append(hold(0x5, 120, 130), hold(0x4, 200, 260)...)},
+	{name: "BLINKY.ch8", frames: 400, input: hold(0x3, 150, 250)},
+	{name: "tank.ch8", frames: 300, input: hold(0x8, 60, 120)},
+	{name: "connect4.ch8", frames: 300, input: append(hold(0x6, 60, 66), hold(0x5, 120, 126)...)},
+	{name: "tictactoe.ch8", frames: 300, input: hold(0x5, 100, 110)},
+	{name: "space.ch8", frames: 300, input: hold(0x5, 100, 110)},
+	{name: "wall.ch8", frames: 300, input: hold(0x1, 60, 120)},
+	{name: "landing.ch8", frames: 300},
+}
+
+// runConformanceROM: loads the ROM with its database settings and a fixed random seed,
+// and runs it feeding the scripted input
+func runConformanceROM(t *testing.T, tc conformanceROM) State {
+	rom, err := LoadROMFile(filepath.Join("..", "roms", tc.name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rand.Seed(1)
+	c := New()
+	if err := c.LoadROM(rom); err != nil {
+		t.Fatal(err)
+	}
+	ctl := NewController(c)
+	for frame := 0; frame < tc.frames; frame++ {
+		for _, in := range tc.input {
+			switch {
+			case in.frame != frame:
+			case in.press:
+				c.PressKey(in.key)
+			default:
+				c.ReleaseKey(in.key)
+			}
+		}
+		ctl.StepFrame()
+	}
+	if c.Fault != nil {
+		t.Fatalf("%s faulted: %v", tc.name, c.Fault)
+	}
+	return c.CurrState
+}
+
+// screenString: the framebuffer as text, a line per row with # for the pixels that are on
+func screenString(s State) string {
+	var b strings.Builder
+	for y := uint8(0); y < ScreenHeight; y++ {
+		for x := uint8(0); x < ScreenWidth; x++ {
+			if s.GetPixel(x, y) {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// screenDiff: overlays two screens made by screenString, pixels only on want are marked
+// with - and pixels only on got with +. Rows that differ are pointed at with a >.
+func screenDiff(want, got string) string {
+	wantRows := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	gotRows := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	var b strings.Builder
+	b.WriteString("  - only in golden, + only in result\n")
+	for y := 0; y < len(wantRows) || y < len(gotRows); y++ {
+		var wantRow, gotRow string
+		if y < len(wantRows) {
+			wantRow = wantRows[y]
+		}
+		if y < len(gotRows) {
+			gotRow = gotRows[y]
+		}
+		marker := "  "
+		if wantRow != gotRow {
+			marker = "> "
+		}
+		b.WriteString(marker)
+		for x := 0; x < len(wantRow) || x < len(gotRow); x++ {
+			w, g := pixelAt(wantRow, x), pixelAt(gotRow, x)
+			switch {
+			case w == g:
+				b.WriteByte(w)
+			case w == '#':
+				b.WriteByte('-')
+			default:
+				b.WriteByte('+')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func pixelAt(row string, x int) byte {
+	if x < len(row) {
+		return row[x]
+	}
+	return '.'
+}
+
+func TestROMConformance(t *testing.T) {
+	for _, tc := range conformanceROMs {
+		t.Run(tc.name, func(t *testing.T) {
+			got := screenString(runConformanceROM(t, tc))
+			golden := filepath.Join("testdata", "golden", strings.TrimSuffix(tc.name, filepath.Ext(tc.name))+".txt")
+
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v, run the tests with -update to create it", err)
+			}
+			if got != string(want) {
+				t.Errorf("the screen of %s after %d frames doesn't match %s:\n%s", tc.name, tc.frames, golden, screenDiff(string(want), got))
+			}
+		})
+	}
+}
+
+func TestScreenDiff(t *testing.T) {
+	t.Run("screenDiff should mark the pixels that changed", func(t *testing.T) {
+		diff := screenDiff("#.\n#.\n", "#.\n.#\n")
+		assert.Equal(t, "  - only in golden, + only in result\n  #.\n> -+\n", diff)
+	})
+}
@@ -0,0 +1,183 @@
+package chip8
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	// FrameRate: the timers and the display run at 60Hz whatever the clock is
+	FrameRate = 60
+	// SlowMotionSpeed: the speed used by slow motion, a fraction of real time
+	SlowMotionSpeed = 0.25
+	// fastForwardBudget: how much real time fast-forward spends running frames on every Update
+	fastForwardBudget = time.Second / FrameRate
+)
+
+var ErrNoROM = errors.New("no ROM loaded")
+
+// Controller runs a Chip8 in real time, one 60Hz frame of TickRate instructions at a time,
+// and lets the frontend pause, step, reset and change the speed of the machine
+type Controller struct {
+	c8 *Chip8
+
+	// Frame is called after every emulated frame with how many instructions ran on it, can be nil
+	Frame func(ticks int)
+
+	paused      bool
+	speed       float64
+	fastForward bool
+	lag         float64
+	// cycleBudget: the machine cycles left on the current frame with COSMAC VIP timing,
+	// negative when the last instruction ran over the frame
+	cycleBudget float64
+}
+
+func NewController(c8 *Chip8) *Controller {
+	return &Controller{c8: c8, speed: 1}
+}
+
+// Reset: loads the current ROM again, keeping the clock
+func (ctl *Controller) Reset() error {
+	if ctl.c8.ROM == nil {
+		return ErrNoROM
+	}
+	tickRate := ctl.c8.TickRate
+	if err := ctl.c8.LoadROM(ctl.c8.ROM); err != nil {
+		return err
+	}
+	ctl.c8.TickRate = tickRate
+	ctl.c8.TickCount, ctl.c8.Executed = 0, 0
+	ctl.lag = 0
+	ctl.cycleBudget = 0
+	return nil
+}
+
+func (ctl *Controller) Pause() {
+	ctl.paused = true
+	ctl.lag = 0
+}
+
+func (ctl *Controller) Resume() {
+	ctl.paused = false
+}
+
+func (ctl *Controller) Paused() bool {
+	return ctl.paused
+}
+
+// TogglePause: pauses a running machine and resumes a paused one
+func (ctl *Controller) TogglePause() {
+	if ctl.paused {
+		ctl.Resume()
+	} else {
+		ctl.Pause()
+	}
+}
+
+// SetClockHz: how many instructions run per second. The clock is rounded to a whole
+// number of instructions per frame, so the timers keep counting down at 60Hz.
+// It has no effect with COSMAC VIP timing.
+func (ctl *Controller) SetClockHz(hz float64) {
+	tickRate := int(math.Round(hz / FrameRate))
+	if tickRate < 1 {
+		tickRate = 1
+	}
+	ctl.c8.TickRate = tickRate
+}
+
+// ClockHz: how many instructions run per second at normal speed, or the clock of
+// the COSMAC VIP with its timing
+func (ctl *Controller) ClockHz() float64 {
+	if ctl.c8.Timing == TimingCOSMACVIP {
+		return VIPClockHz
+	}
+	return float64(ctl.c8.tickRate() * FrameRate)
+}
+
+// SetSpeed: runs the machine faster or slower than real time, 1 is real time
+func (ctl *Controller) SetSpeed(speed float64) {
+	if speed <= 0 {
+		speed = 1
+	}
+	ctl.speed = speed
+}
+
+func (ctl *Controller) Speed() float64 {
+	return ctl.speed
+}
+
+// SetFastForward: while fast-forwarding the machine runs as many frames as it can, ignoring the speed
+func (ctl *Controller) SetFastForward(fastForward bool) {
+	ctl.fastForward = fastForward
+	ctl.lag = 0
+}
+
+func (ctl *Controller) FastForward() bool {
+	return ctl.fastForward
+}
+
+// Update: runs the frames due after elapsed seconds of real time, returns how many ran
+func (ctl *Controller) Update(elapsed float64) int {
+	if ctl.paused {
+		return 0
+	}
+
+	if ctl.fastForward {
+		frames := 0
+		for start := time.Now(); frames == 0 || time.Since(start) < fastForwardBudget; frames++ {
+			ctl.runFrame()
+		}
+		return frames
+	}
+
+	const secsPerFrame = 1.0 / FrameRate
+	ctl.lag += elapsed * ctl.speed
+	frames := 0
+	for ctl.lag >= secsPerFrame {
+		ctl.runFrame()
+		ctl.lag -= secsPerFrame
+		frames++
+	}
+	return frames
+}
+
+// StepFrame: runs a single frame, used to advance frame by frame while paused
+func (ctl *Controller) StepFrame() {
+	ctl.runFrame()
+}
+
+func (ctl *Controller) runFrame() {
+	var ticks int
+	if ctl.c8.Timing == TimingCOSMACVIP {
+		ticks = ctl.runVIPFrame()
+	} else {
+		ticks = ctl.c8.tickRate()
+		ctl.c8.Run(ticks)
+		ctl.c8.EndFrame()
+	}
+	if ctl.Frame != nil {
+		ctl.Frame(ticks)
+	}
+}
+
+// runVIPFrame: runs instructions until the machine cycles of the frame are spent or
+// a Dxyn waits for the display, which idles the rest of the frame
+func (ctl *Controller) runVIPFrame() int {
+	c8 := ctl.c8
+	ticks := 0
+	ctl.cycleBudget += VIPCyclesPerFrame
+	for ctl.cycleBudget > 0 && c8.Fault == nil {
+		before := c8.Cycles
+		c8.Tick(1 / ctl.ClockHz())
+		if c8.WaitingForVBlank() {
+			ctl.cycleBudget = 0
+			break
+		}
+		ctl.cycleBudget -= float64(c8.Cycles - before)
+		ticks++
+	}
+	c8.EndFrame()
+	return ticks
+}
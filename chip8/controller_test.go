@@ -0,0 +1,94 @@
+package chip8
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingROM: a ROM that adds 1 to V0 forever
+var countingROM = &ROM{Name: "counting.ch8", Data: []uint8{0x70, 0x01, 0x12, 0x00}}
+
+func TestController(t *testing.T) {
+	t.Run("Update should run TickRate instructions for every 60th of a second", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadROM(countingROM))
+		c.TickRate = 10
+		ctl := NewController(c)
+		var frameTicks []int
+		ctl.Frame = func(ticks int) {
+			frameTicks = append(frameTicks, ticks)
+		}
+
+		assert.Equal(t, 3, ctl.Update(3.5/FrameRate))
+		assert.Equal(t, []int{10, 10, 10}, frameTicks)
+		assert.Equal(t, int64(30), c.TickCount)
+
+		assert.Equal(t, 1, ctl.Update(0.5/FrameRate), "The leftover time should be kept for the next update")
+	})
+
+	t.Run("Pause should stop Update but not StepFrame", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadROM(countingROM))
+		ctl := NewController(c)
+
+		ctl.Pause()
+		assert.True(t, ctl.Paused())
+		assert.Equal(t, 0, ctl.Update(1))
+		assert.Equal(t, int64(0), c.TickCount)
+
+		ctl.StepFrame()
+		assert.Equal(t, int64(c.TickRate), c.TickCount, "StepFrame should run a single frame")
+
+		ctl.Resume()
+		assert.Equal(t, 1, ctl.Update(1.0/FrameRate))
+	})
+
+	t.Run("SetClockHz should round the clock to whole instructions per frame", func(t *testing.T) {
+		ctl := NewController(New())
+		ctl.SetClockHz(1000)
+		assert.Equal(t, 17, ctl.c8.TickRate)
+		assert.Equal(t, float64(1020), ctl.ClockHz())
+
+		ctl.SetClockHz(0)
+		assert.Equal(t, float64(FrameRate), ctl.ClockHz(), "The clock should run at least one instruction per frame")
+	})
+
+	t.Run("SetSpeed should scale real time", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadROM(countingROM))
+		ctl := NewController(c)
+
+		ctl.SetSpeed(SlowMotionSpeed)
+		assert.Equal(t, 1, ctl.Update(4.0/FrameRate))
+		ctl.SetSpeed(2)
+		assert.Equal(t, 2, ctl.Update(1.0/FrameRate))
+	})
+
+	t.Run("Fast-forward should run at least a frame on every update", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadROM(countingROM))
+		ctl := NewController(c)
+
+		ctl.SetFastForward(true)
+		assert.GreaterOrEqual(t, ctl.Update(0), 1)
+	})
+
+	t.Run("Reset should reload the ROM and keep the clock", func(t *testing.T) {
+		c := New()
+		ctl := NewController(c)
+		assert.True(t, errors.Is(ctl.Reset(), ErrNoROM))
+
+		assert.NoError(t, c.LoadROM(countingROM))
+		ctl.SetClockHz(600)
+		ctl.Update(1)
+		assert.NotZero(t, c.CurrState.V[0x0])
+
+		assert.NoError(t, ctl.Reset())
+		assert.Zero(t, c.CurrState.V[0x0])
+		assert.Equal(t, uint16(ProgramStartAddress), c.CurrState.PC)
+		assert.Equal(t, 10, c.TickRate)
+		assert.Equal(t, int64(0), c.TickCount)
+	})
+}
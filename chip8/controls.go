@@ -0,0 +1,61 @@
+package chip8
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	pauseKey        = sdl.K_p
+	frameAdvanceKey = sdl.K_n
+	fastForwardKey  = sdl.K_F11
+	slowMotionKey   = sdl.K_F12
+	clockUpKey      = sdl.K_EQUALS
+	clockDownKey    = sdl.K_MINUS
+)
+
+// handleControlKey: pause, frame advance, fast-forward, slow motion and the clock,
+// returns false if the key isn't one of them
+func (g *SDLGraphics) handleControlKey(key sdl.Keycode) bool {
+	ctl := g.controller
+	switch key {
+	case pauseKey:
+		ctl.TogglePause()
+		g.memoryPanel.typedDigits = 0
+	case frameAdvanceKey:
+		if !ctl.Paused() {
+			return false
+		}
+		ctl.StepFrame()
+	case fastForwardKey:
+		ctl.SetFastForward(!ctl.FastForward())
+	case slowMotionKey:
+		if ctl.Speed() == 1 {
+			ctl.SetSpeed(SlowMotionSpeed)
+		} else {
+			ctl.SetSpeed(1)
+		}
+	case clockUpKey:
+		ctl.SetClockHz(ctl.ClockHz() + FrameRate)
+	case clockDownKey:
+		ctl.SetClockHz(ctl.ClockHz() - FrameRate)
+	default:
+		return false
+	}
+	return true
+}
+
+// speedLabel: how fast the machine is running, for the HUD
+func (g *SDLGraphics) speedLabel() string {
+	ctl := g.controller
+	switch {
+	case ctl.Paused():
+		return "PAUSED"
+	case ctl.FastForward():
+		return "FAST-FORWARD"
+	case ctl.Speed() != 1:
+		return fmt.Sprintf("x%.2f", ctl.Speed())
+	}
+	return "x1"
+}
@@ -0,0 +1,260 @@
+package chip8
+
+import "fmt"
+
+// Operation is what an instruction does, one for each chip-8 instruction
+type Operation uint8
+
+const (
+	OpInvalid     Operation = iota
+	OpSYS                   // 0nnn
+	OpCLS                   // 00E0
+	OpRET                   // 00EE
+	OpJP                    // 1nnn
+	OpCALL                  // 2nnn
+	OpSEByte                // 3xkk
+	OpSNEByte               // 4xkk
+	OpSERegister            // 5xy0
+	OpLDByte                // 6xkk
+	OpADDByte               // 7xkk
+	OpLDRegister            // 8xy0
+	OpOR                    // 8xy1
+	OpAND                   // 8xy2
+	OpXOR                   // 8xy3
+	OpADDRegister           // 8xy4
+	OpSUB                   // 8xy5
+	OpSHR                   // 8xy6
+	OpSUBN                  // 8xy7
+	OpSHL                   // 8xyE
+	OpSNERegister           // 9xy0
+	OpLDI                   // Annn
+	OpJPV0                  // Bnnn
+	OpRND                   // Cxkk
+	OpDRW                   // Dxyn
+	OpSKP                   // Ex9E
+	OpSKNP                  // ExA1
+	OpLDVxDT                // Fx07
+	OpLDVxK                 // Fx0A
+	OpLDDTVx                // Fx15
+	OpLDSTVx                // Fx18
+	OpADDI                  // Fx1E
+	OpLDF                   // Fx29
+	OpLDB                   // Fx33
+	OpLDIVx                 // Fx55
+	OpLDVxI                 // Fx65
+	operationCount
+)
+
+// operationNames: the operations in the notation of Cowgod's Chip-8 Technical Reference
+var operationNames = [operationCount]string{
+	OpInvalid: "invalid", OpSYS: "SYS addr", OpCLS: "CLS", OpRET: "RET", OpJP: "JP addr", OpCALL: "CALL addr",
+	OpSEByte: "SE Vx, byte", OpSNEByte: "SNE Vx, byte", OpSERegister: "SE Vx, Vy", OpLDByte: "LD Vx, byte",
+	OpADDByte: "ADD Vx, byte", OpLDRegister: "LD Vx, Vy", OpOR: "OR Vx, Vy", OpAND: "AND Vx, Vy",
+	OpXOR: "XOR Vx, Vy", OpADDRegister: "ADD Vx, Vy", OpSUB: "SUB Vx, Vy", OpSHR: "SHR Vx, Vy",
+	OpSUBN: "SUBN Vx, Vy", OpSHL: "SHL Vx, Vy", OpSNERegister: "SNE Vx, Vy", OpLDI: "LD I, addr",
+	OpJPV0: "JP V0, addr", OpRND: "RND Vx, byte", OpDRW: "DRW Vx, Vy, nibble", OpSKP: "SKP Vx",
+	OpSKNP: "SKNP Vx", OpLDVxDT: "LD Vx, DT", OpLDVxK: "LD Vx, K", OpLDDTVx: "LD DT, Vx",
+	OpLDSTVx: "LD ST, Vx", OpADDI: "ADD I, Vx", OpLDF: "LD F, Vx", OpLDB: "LD B, Vx",
+	OpLDIVx: "LD [I], Vx", OpLDVxI: "LD Vx, [I]",
+}
+
+func (op Operation) String() string {
+	if op >= operationCount {
+		return fmt.Sprintf("Operation(%d)", int(op))
+	}
+	return operationNames[op]
+}
+
+// Instruction is an opcode decoded once, with every operand already extracted
+type Instruction struct {
+	Opcode uint16
+	Op     Operation
+	X, Y   uint8
+	// N is the lowest nibble, the height of Dxyn
+	N   uint8
+	KK  uint8
+	NNN uint16
+}
+
+// decodeTable: the operation of each opcode by its highest nibble, 0x0, 0x8, 0xE and 0xF
+// need the lowest bits too and are looked up on the tables below
+var decodeTable = [0x10]Operation{
+	0x1: OpJP,
+	0x2: OpCALL,
+	0x3: OpSEByte,
+	0x4: OpSNEByte,
+	0x6: OpLDByte,
+	0x7: OpADDByte,
+	0x9: OpSNERegister,
+	0xA: OpLDI,
+	0xB: OpJPV0,
+	0xC: OpRND,
+	0xD: OpDRW,
+}
+
+// aluTable: 8xyn operations by n
+var aluTable = [0x10]Operation{
+	0x0: OpLDRegister,
+	0x1: OpOR,
+	0x2: OpAND,
+	0x3: OpXOR,
+	0x4: OpADDRegister,
+	0x5: OpSUB,
+	0x6: OpSHR,
+	0x7: OpSUBN,
+	0xE: OpSHL,
+}
+
+// keyTable and miscTable: Exkk and Fxkk operations by kk
+var (
+	keyTable = [0x100]Operation{
+		0x9E: OpSKP,
+		0xA1: OpSKNP,
+	}
+	miscTable = [0x100]Operation{
+		0x07: OpLDVxDT,
+		0x0A: OpLDVxK,
+		0x15: OpLDDTVx,
+		0x18: OpLDSTVx,
+		0x1E: OpADDI,
+		0x29: OpLDF,
+		0x33: OpLDB,
+		0x55: OpLDIVx,
+		0x65: OpLDVxI,
+	}
+)
+
+// Decode: splits the opcode into its operation and operands
+func Decode(opcode uint16) Instruction {
+	in := Instruction{
+		Opcode: opcode,
+		X:      uint8(opcode & 0x0F00 >> ByteSize),
+		Y:      uint8(opcode & 0x00F0 >> NibbleSize),
+		N:      uint8(opcode & 0x000F),
+		KK:     uint8(opcode & 0x00FF),
+		NNN:    opcode & 0x0FFF,
+	}
+
+	switch opcode >> (NibbleSize * 3) {
+	case 0x0:
+		switch opcode {
+		case 0x00E0:
+			in.Op = OpCLS
+		case 0x00EE:
+			in.Op = OpRET
+		default:
+			in.Op = OpSYS
+		}
+	case 0x5:
+		if in.N == 0x0 {
+			in.Op = OpSERegister
+		}
+	case 0x8:
+		in.Op = aluTable[in.N]
+	case 0x9:
+		if in.N == 0x0 {
+			in.Op = OpSNERegister
+		}
+	case 0xE:
+		in.Op = keyTable[in.KK]
+	case 0xF:
+		in.Op = miscTable[in.KK]
+	default:
+		in.Op = decodeTable[opcode>>(NibbleSize*3)]
+	}
+	return in
+}
+
+// handlers: what runs each operation, they change the current state in place
+var handlers = [operationCount]func(c *Chip8, in Instruction){
+	OpInvalid:     (*Chip8).invalidInstruction,
+	OpSYS:         (*Chip8).syscall,
+	OpCLS:         (*Chip8).clearScreen,
+	OpRET:         (*Chip8).returnFromSubroutine,
+	OpJP:          (*Chip8).jumpToAddress,
+	OpCALL:        (*Chip8).callSubroutine,
+	OpSEByte:      (*Chip8).skipIfVxEqualValue,
+	OpSNEByte:     (*Chip8).skipIfVxNotEqualValue,
+	OpSERegister:  (*Chip8).skipIfVxEqualVy,
+	OpLDByte:      (*Chip8).loadIntoVx,
+	OpADDByte:     (*Chip8).addToVx,
+	OpLDRegister:  (*Chip8).loadVxIntoVy,
+	OpOR:          (*Chip8).loadBitwiseVxOrVyIntoVx,
+	OpAND:         (*Chip8).loadBitwiseVxAndVyIntoVx,
+	OpXOR:         (*Chip8).loadBitwiseVxExclusiveOrVyIntoVx,
+	OpADDRegister: (*Chip8).addVyToVx,
+	OpSUB:         (*Chip8).subtractVxByVy,
+	OpSHR:         (*Chip8).shiftVxRight,
+	OpSUBN:        (*Chip8).loadVySubtractedByVxIntoVx,
+	OpSHL:         (*Chip8).shiftVxLeft,
+	OpSNERegister: (*Chip8).skipIfVxNotEqualVy,
+	OpLDI:         (*Chip8).loadAddressIntoI,
+	OpJPV0:        (*Chip8).jumpToAddressPlusV0,
+	OpRND:         (*Chip8).loadRandomValueBitwiseAndValueIntoVx,
+	OpDRW:         (*Chip8).drawSprite,
+	OpSKP:         (*Chip8).skipIfVxKeyIsPressed,
+	OpSKNP:        (*Chip8).skipIfVxKeyIsNotPressed,
+	OpLDVxDT:      (*Chip8).loadDelayTimerIntoVx,
+	OpLDVxK:       (*Chip8).waitButtonPressAndLoadIntoVx,
+	OpLDDTVx:      (*Chip8).loadVxIntoDelayTimer,
+	OpLDSTVx:      (*Chip8).loadVxIntoSoundTimer,
+	OpADDI:        (*Chip8).addVxToI,
+	OpLDF:         (*Chip8).loadVxDigitSpriteAddressIntoI,
+	OpLDB:         (*Chip8).loadVxDigitsIntoI,
+	OpLDIVx:       (*Chip8).loadRangeV0ToVxIntoMemoryStartingFromI,
+	OpLDVxI:       (*Chip8).loadMemoryStartingFromIIntoRangeV0ToVx,
+}
+
+// execute: runs the instruction on the current state
+func (c *Chip8) execute(in Instruction) {
+	handlers[in.Op](c, in)
+}
+
+// instructionCache keeps the decoded instruction of each address, so loops don't decode
+// the same opcodes over and over. Entries are dropped when their bytes are written.
+type instructionCache struct {
+	valid        [MemorySize]bool
+	instructions [MemorySize]Instruction
+}
+
+// fetchInstruction: the instruction at PC, decoded once and then taken from the cache.
+// Fetches are read through the memory accessors when a read hook is watching them or
+// when the opcode runs past the end of memory.
+func (c *Chip8) fetchInstruction() Instruction {
+	pc := c.CurrState.PC
+	if c.Hooks.MemoryRead != nil || int(pc) >= MemorySize-1 || c.cache == nil {
+		return Decode(c.fetch(&c.CurrState))
+	}
+	if c.cache.valid[pc] {
+		return c.cache.instructions[pc]
+	}
+	in := Decode(uint16(c.CurrState.Memory[pc])<<ByteSize | uint16(c.CurrState.Memory[pc+1]))
+	c.cache.instructions[pc] = in
+	c.cache.valid[pc] = true
+	return in
+}
+
+// invalidate: drops the cached instructions that include the byte at the address, and
+// the translated blocks if any of them does
+func (c *Chip8) invalidate(addr uint16) {
+	if c.jit != nil && c.jit.code[addr&AddressMask] {
+		c.flushJIT()
+	}
+	if c.cache == nil {
+		return
+	}
+	c.cache.valid[addr&AddressMask] = false
+	c.cache.valid[(addr-1)&AddressMask] = false
+}
+
+// InvalidateInstructionCache: drops every decoded instruction and translated block. Writes
+// done through WriteMemory and the instructions are tracked already, this is only needed
+// after changing CurrState.Memory directly.
+func (c *Chip8) InvalidateInstructionCache() {
+	c.flushJIT()
+	if c.cache == nil {
+		c.cache = &instructionCache{}
+		return
+	}
+	c.cache.valid = [MemorySize]bool{}
+}
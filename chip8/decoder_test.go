@@ -0,0 +1,152 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	t.Run("Decode should find the operation of every instruction", func(t *testing.T) {
+		operations := map[uint16]Operation{
+			0x0123: OpSYS, 0x00E0: OpCLS, 0x00EE: OpRET, 0x1234: OpJP, 0x2234: OpCALL,
+			0x3122: OpSEByte, 0x4122: OpSNEByte, 0x5120: OpSERegister, 0x6122: OpLDByte, 0x7122: OpADDByte,
+			0x8120: OpLDRegister, 0x8121: OpOR, 0x8122: OpAND, 0x8123: OpXOR, 0x8124: OpADDRegister,
+			0x8125: OpSUB, 0x8126: OpSHR, 0x8127: OpSUBN, 0x812E: OpSHL, 0x9120: OpSNERegister,
+			0xA123: OpLDI, 0xB123: OpJPV0, 0xC1FF: OpRND, 0xD125: OpDRW, 0xE19E: OpSKP, 0xE1A1: OpSKNP,
+			0xF107: OpLDVxDT, 0xF10A: OpLDVxK, 0xF115: OpLDDTVx, 0xF118: OpLDSTVx, 0xF11E: OpADDI,
+			0xF129: OpLDF, 0xF133: OpLDB, 0xF155: OpLDIVx, 0xF165: OpLDVxI,
+		}
+		for opcode, op := range operations {
+			assert.Equal(t, op, Decode(opcode).Op, "%04X", opcode)
+		}
+		assert.Len(t, operations, int(operationCount)-1, "Every operation should be covered")
+	})
+
+	t.Run("Decode should leave unknown opcodes invalid", func(t *testing.T) {
+		for _, opcode := range []uint16{0x5121, 0x8128, 0x812F, 0x9121, 0xE100, 0xF100, 0xF1FF} {
+			assert.Equal(t, OpInvalid, Decode(opcode).Op, "%04X", opcode)
+		}
+	})
+
+	t.Run("Decode should extract the operands", func(t *testing.T) {
+		in := Decode(0xD3A7)
+		assert.Equal(t, Instruction{Opcode: 0xD3A7, Op: OpDRW, X: 0x3, Y: 0xA, N: 0x7, KK: 0xA7, NNN: 0x3A7}, in)
+	})
+
+	t.Run("Tick should run the instruction in place", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadGame([]uint8{0x60, 0x05, 0x70, 0x01}))
+		c.Tick(0)
+		c.Tick(0)
+		assert.Equal(t, uint8(0x06), c.CurrState.V[0x0])
+		assert.Equal(t, ProgramStartAddress+4, c.CurrState.PC)
+		assert.Empty(t, c.History(), "No history should be kept without a limit")
+	})
+
+	t.Run("Writes to memory should drop the cached instructions", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadGame([]uint8{0x12, 0x00})) // JP 0x200
+		c.Tick(0)
+		c.Tick(0)
+		assert.Equal(t, ProgramStartAddress, c.CurrState.PC, "The jump should be cached by now")
+
+		c.WriteMemory(0x201, 0x02)
+		c.Tick(0)
+		assert.Equal(t, uint16(0x202), c.CurrState.PC, "The jump should have been decoded again")
+	})
+
+	t.Run("Fx55 should drop the cached instructions it overwrites", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadGame([]uint8{
+			0x12, 0x02, // 200: JP 0x202, cached on the first run
+			0x60, 0x63, // 202: LD V0, 0x63
+			0x61, 0x33, // 204: LD V1, 0x33
+			0xA2, 0x00, // 206: LD I, 0x200
+			0xF1, 0x55, // 208: LD [I], V1 writes LD V3, 0x33 over the jump
+			0x12, 0x00, // 20A: JP 0x200
+		}))
+		for i := 0; i < 7; i++ {
+			c.Tick(0)
+		}
+		assert.Equal(t, uint8(0x33), c.CurrState.V[0x3])
+	})
+
+	t.Run("History should keep the last HistoryLimit states", func(t *testing.T) {
+		c := New()
+		c.HistoryLimit = 3
+		assert.NoError(t, c.LoadROM(countingROM))
+		for i := 0; i < 8; i++ {
+			c.Tick(0)
+		}
+		history := c.History()
+		assert.Len(t, history, 3)
+		assert.Equal(t, uint8(3), history[0].V[0x0])
+		assert.Equal(t, uint8(4), history[2].V[0x0])
+		assert.Equal(t, uint16(0x202), history[2].PC, "The last state should be the one before the last instruction")
+
+		c.HistoryLimit = 1
+		c.Tick(0)
+		history = c.History()
+		assert.Len(t, history, 1)
+		assert.Equal(t, ProgramStartAddress, history[0].PC)
+	})
+
+	t.Run("Log should get every instruction run", func(t *testing.T) {
+		var log bytes.Buffer
+		c := New()
+		c.Log = &log
+		assert.NoError(t, c.LoadGame([]uint8{0x60, 0x05}))
+		c.Tick(0)
+		assert.Equal(t, "PC 200\tOP 6005\t"+Disassemble(0x6005)+"\n", log.String())
+	})
+}
+
+// benchmarkROM: a busy loop that counts, compares, stores and reads memory like games do
+var benchmarkROM = []uint8{
+	0xA3, 0x00, // 200: LD I, 0x300
+	0x70, 0x01, // 202: ADD V0, 1
+	0x81, 0x04, // 204: ADD V1, V0
+	0x82, 0x13, // 206: XOR V2, V1
+	0x30, 0x00, // 208: SE V0, 0
+	0x83, 0x06, // 20A: SHR V3
+	0xF2, 0x55, // 20C: LD [I], V2
+	0xF2, 0x65, // 20E: LD V2, [I]
+	0x12, 0x02, // 210: JP 0x202
+}
+
+func BenchmarkTick(b *testing.B) {
+	c := New()
+	if err := c.LoadGame(benchmarkROM); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		c.Tick(0)
+	}
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "instructions/s")
+}
+
+func BenchmarkDecode(b *testing.B) {
+	b.ReportAllocs()
+	var in Instruction
+	for i := 0; i < b.N; i++ {
+		in = Decode(uint16(i))
+	}
+	_ = in
+}
+
+func BenchmarkExecuteOpcode(b *testing.B) {
+	c := New()
+	if err := c.LoadGame(benchmarkROM); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.ExecuteOpcode(0x8124)
+	}
+}
@@ -0,0 +1,103 @@
+package chip8
+
+import "fmt"
+
+// Disassemble: returns the mnemonic of the opcode, using the same notation as
+// Cowgod's Chip-8 Technical Reference (e.g. "LD V1, 0x2A")
+func Disassemble(opcode uint16) string {
+	switch opcode {
+	case 0x00E0:
+		return "CLS"
+	case 0x00EE:
+		return "RET"
+	}
+
+	addr := opcode & 0x0FFF
+	x := uint8(opcode & 0x0F00 >> ByteSize)
+	y := uint8(opcode & 0x00F0 >> NibbleSize)
+	value := uint8(opcode & 0x00FF)
+	nibble := uint8(opcode & 0x000F)
+
+	switch opcode >> (NibbleSize * 3) {
+	case 0x0:
+		return fmt.Sprintf("SYS 0x%03X", addr)
+	case 0x1:
+		return fmt.Sprintf("JP 0x%03X", addr)
+	case 0x2:
+		return fmt.Sprintf("CALL 0x%03X", addr)
+	case 0x3:
+		return fmt.Sprintf("SE V%X, 0x%02X", x, value)
+	case 0x4:
+		return fmt.Sprintf("SNE V%X, 0x%02X", x, value)
+	case 0x5:
+		if nibble == 0x0 {
+			return fmt.Sprintf("SE V%X, V%X", x, y)
+		}
+	case 0x6:
+		return fmt.Sprintf("LD V%X, 0x%02X", x, value)
+	case 0x7:
+		return fmt.Sprintf("ADD V%X, 0x%02X", x, value)
+	case 0x8:
+		switch nibble {
+		case 0x0:
+			return fmt.Sprintf("LD V%X, V%X", x, y)
+		case 0x1:
+			return fmt.Sprintf("OR V%X, V%X", x, y)
+		case 0x2:
+			return fmt.Sprintf("AND V%X, V%X", x, y)
+		case 0x3:
+			return fmt.Sprintf("XOR V%X, V%X", x, y)
+		case 0x4:
+			return fmt.Sprintf("ADD V%X, V%X", x, y)
+		case 0x5:
+			return fmt.Sprintf("SUB V%X, V%X", x, y)
+		case 0x6:
+			return fmt.Sprintf("SHR V%X, V%X", x, y)
+		case 0x7:
+			return fmt.Sprintf("SUBN V%X, V%X", x, y)
+		case 0xE:
+			return fmt.Sprintf("SHL V%X, V%X", x, y)
+		}
+	case 0x9:
+		if nibble == 0x0 {
+			return fmt.Sprintf("SNE V%X, V%X", x, y)
+		}
+	case 0xA:
+		return fmt.Sprintf("LD I, 0x%03X", addr)
+	case 0xB:
+		return fmt.Sprintf("JP V0, 0x%03X", addr)
+	case 0xC:
+		return fmt.Sprintf("RND V%X, 0x%02X", x, value)
+	case 0xD:
+		return fmt.Sprintf("DRW V%X, V%X, %d", x, y, nibble)
+	case 0xE:
+		switch value {
+		case 0x9E:
+			return fmt.Sprintf("SKP V%X", x)
+		case 0xA1:
+			return fmt.Sprintf("SKNP V%X", x)
+		}
+	case 0xF:
+		switch value {
+		case 0x07:
+			return fmt.Sprintf("LD V%X, DT", x)
+		case 0x0A:
+			return fmt.Sprintf("LD V%X, K", x)
+		case 0x15:
+			return fmt.Sprintf("LD DT, V%X", x)
+		case 0x18:
+			return fmt.Sprintf("LD ST, V%X", x)
+		case 0x1E:
+			return fmt.Sprintf("ADD I, V%X", x)
+		case 0x29:
+			return fmt.Sprintf("LD F, V%X", x)
+		case 0x33:
+			return fmt.Sprintf("LD B, V%X", x)
+		case 0x55:
+			return fmt.Sprintf("LD [I], V%X", x)
+		case 0x65:
+			return fmt.Sprintf("LD V%X, [I]", x)
+		}
+	}
+	return fmt.Sprintf("DW 0x%04X", opcode)
+}
@@ -0,0 +1,58 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisassemble(t *testing.T) {
+	t.Run("Should return the mnemonic of every instruction", func(t *testing.T) {
+		cases := map[uint16]string{
+			0x00E0: "CLS",
+			0x00EE: "RET",
+			0x0123: "SYS 0x123",
+			0x1228: "JP 0x228",
+			0x2ABC: "CALL 0xABC",
+			0x3A12: "SE VA, 0x12",
+			0x4B34: "SNE VB, 0x34",
+			0x5120: "SE V1, V2",
+			0x62FF: "LD V2, 0xFF",
+			0x7301: "ADD V3, 0x01",
+			0x8120: "LD V1, V2",
+			0x8121: "OR V1, V2",
+			0x8122: "AND V1, V2",
+			0x8123: "XOR V1, V2",
+			0x8124: "ADD V1, V2",
+			0x8125: "SUB V1, V2",
+			0x8126: "SHR V1, V2",
+			0x8127: "SUBN V1, V2",
+			0x812E: "SHL V1, V2",
+			0x9120: "SNE V1, V2",
+			0xA2F0: "LD I, 0x2F0",
+			0xB300: "JP V0, 0x300",
+			0xC40F: "RND V4, 0x0F",
+			0xD125: "DRW V1, V2, 5",
+			0xE59E: "SKP V5",
+			0xE5A1: "SKNP V5",
+			0xF607: "LD V6, DT",
+			0xF60A: "LD V6, K",
+			0xF615: "LD DT, V6",
+			0xF618: "LD ST, V6",
+			0xF61E: "ADD I, V6",
+			0xF629: "LD F, V6",
+			0xF633: "LD B, V6",
+			0xF655: "LD [I], V6",
+			0xF665: "LD V6, [I]",
+		}
+		for opcode, expected := range cases {
+			assert.Equal(t, expected, Disassemble(opcode), "Opcode %04X", opcode)
+		}
+	})
+
+	t.Run("Should show unknown opcodes as raw data", func(t *testing.T) {
+		assert.Equal(t, "DW 0x5121", Disassemble(0x5121))
+		assert.Equal(t, "DW 0x812F", Disassemble(0x812F))
+		assert.Equal(t, "DW 0xFFFF", Disassemble(0xFFFF))
+	})
+}
@@ -0,0 +1,117 @@
+package chip8
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// DisplayFilters are the post-processing steps applied between State.Graphics
+// and whatever ends up on the screen. The zero value shows the raw framebuffer.
+type DisplayFilters struct {
+	// Phosphor is how much of the previous intensity a pixel keeps every frame
+	// once it's turned off, from 0 (no persistence) to 1 (never fades).
+	Phosphor float64
+	// AntiFlicker ORs the current frame with the previous one, so sprites that
+	// are erased and redrawn every frame don't blink.
+	AntiFlicker bool
+	// Scanlines darkens the last output row of every chip-8 pixel, from 0 (off) to 1 (black).
+	Scanlines float64
+	// PixelGrid draws the last output column and row of every chip-8 pixel with the grid color.
+	PixelGrid bool
+}
+
+type Display struct {
+	Filters    DisplayFilters
+	Foreground color.RGBA
+	Background color.RGBA
+	Grid       color.RGBA
+	Scale      int
+
+	previous  [ScreenHeight]uint64
+	intensity [ScreenHeight][ScreenWidth]float64
+	image     *image.RGBA
+}
+
+// Push: feeds a new frame into the filters, it should be called once per 60Hz frame
+func (d *Display) Push(graphics [ScreenHeight]uint64) {
+	for y := 0; y < ScreenHeight; y++ {
+		row := graphics[y]
+		if d.Filters.AntiFlicker {
+			row |= d.previous[y]
+		}
+		for x := 0; x < ScreenWidth; x++ {
+			if row&(FirstScreenBitMask>>x) != 0 {
+				d.intensity[y][x] = 1
+			} else {
+				d.intensity[y][x] *= d.Filters.Phosphor
+			}
+		}
+	}
+	d.previous = graphics
+}
+
+// Intensity: how lit the pixel at x, y is after the filters, from 0 to 1
+func (d *Display) Intensity(x, y int) float64 {
+	return d.intensity[y][x]
+}
+
+// Render: draws the filtered frame into an RGBA image of ScreenWidth*Scale by ScreenHeight*Scale.
+// The returned image is reused by the next call.
+func (d *Display) Render() *image.RGBA {
+	scale := d.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	bounds := image.Rect(0, 0, ScreenWidth*scale, ScreenHeight*scale)
+	if d.image == nil || d.image.Bounds() != bounds {
+		d.image = image.NewRGBA(bounds)
+	}
+
+	for y := 0; y < ScreenHeight; y++ {
+		for x := 0; x < ScreenWidth; x++ {
+			pixel := blend(d.Background, d.Foreground, d.intensity[y][x])
+			for sy := 0; sy < scale; sy++ {
+				for sx := 0; sx < scale; sx++ {
+					c := pixel
+					lastRow, lastCol := scale > 1 && sy == scale-1, scale > 1 && sx == scale-1
+					if d.Filters.PixelGrid && (lastRow || lastCol) {
+						c = d.Grid
+					} else if lastRow && d.Filters.Scanlines > 0 {
+						c = blend(c, color.RGBA{A: c.A}, d.Filters.Scanlines)
+					}
+					d.image.SetRGBA(x*scale+sx, y*scale+sy, c)
+				}
+			}
+		}
+	}
+
+	return d.image
+}
+
+// WritePNG: encodes the current filtered frame as PNG, for headless exports
+func (d *Display) WritePNG(w io.Writer) error {
+	return png.Encode(w, d.Render())
+}
+
+func blend(from, to color.RGBA, amount float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*amount + 0.5)
+	}
+	return color.RGBA{
+		R: mix(from.R, to.R),
+		G: mix(from.G, to.G),
+		B: mix(from.B, to.B),
+		A: mix(from.A, to.A),
+	}
+}
+
+func NewDisplay(scale int) *Display {
+	return &Display{
+		Foreground: color.RGBA{R: 194, G: 62, B: 128, A: 255},
+		Background: color.RGBA{A: 255},
+		Grid:       color.RGBA{R: 24, G: 24, B: 24, A: 255},
+		Scale:      scale,
+	}
+}
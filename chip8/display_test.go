@@ -0,0 +1,67 @@
+package chip8
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplay(t *testing.T) {
+	lit := [ScreenHeight]uint64{FirstScreenBitMask}
+
+	t.Run("Without filters the display should show the raw framebuffer", func(t *testing.T) {
+		d := NewDisplay(1)
+		d.Push(lit)
+		assert.Equal(t, 1.0, d.Intensity(0, 0), "Lit pixel should be fully bright")
+		d.Push([ScreenHeight]uint64{})
+		assert.Equal(t, 0.0, d.Intensity(0, 0), "Pixel should turn off right away")
+	})
+
+	t.Run("Phosphor should fade pixels out across frames", func(t *testing.T) {
+		d := NewDisplay(1)
+		d.Filters.Phosphor = 0.5
+		d.Push(lit)
+		d.Push([ScreenHeight]uint64{})
+		assert.Equal(t, 0.5, d.Intensity(0, 0), "Pixel should keep half of its intensity")
+		d.Push([ScreenHeight]uint64{})
+		assert.Equal(t, 0.25, d.Intensity(0, 0), "Pixel should keep fading")
+	})
+
+	t.Run("Anti flicker should keep pixels lit on the frame after they were erased", func(t *testing.T) {
+		d := NewDisplay(1)
+		d.Filters.AntiFlicker = true
+		d.Push(lit)
+		d.Push([ScreenHeight]uint64{})
+		assert.Equal(t, 1.0, d.Intensity(0, 0), "Pixel should still be lit")
+		d.Push([ScreenHeight]uint64{})
+		assert.Equal(t, 0.0, d.Intensity(0, 0), "Pixel should turn off after two empty frames")
+	})
+
+	t.Run("Render should scale pixels and apply scanlines and grid", func(t *testing.T) {
+		d := NewDisplay(3)
+		d.Filters.Scanlines = 1
+		d.Push(lit)
+		img := d.Render()
+		assert.Equal(t, ScreenWidth*3, img.Bounds().Dx())
+		assert.Equal(t, d.Foreground, img.RGBAAt(0, 0), "Scaled pixel should use the foreground color")
+		assert.Equal(t, color.RGBA{A: 255}, img.RGBAAt(0, 2), "Scanline row should be darkened")
+		assert.Equal(t, d.Background, img.RGBAAt(3, 0), "Unlit pixel should use the background color")
+
+		d.Filters.PixelGrid = true
+		img = d.Render()
+		assert.Equal(t, d.Grid, img.RGBAAt(2, 0), "Grid column should use the grid color")
+	})
+
+	t.Run("WritePNG should export the filtered frame", func(t *testing.T) {
+		d := NewDisplay(2)
+		d.Push(lit)
+		var buf bytes.Buffer
+		assert.NoError(t, d.WritePNG(&buf))
+		img, err := png.Decode(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, ScreenHeight*2, img.Bounds().Dy())
+	})
+}
@@ -0,0 +1,247 @@
+package chip8
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FontSet is the hex digits sprites loaded into memory for Fx29.
+// Small has 16 glyphs of 5 bytes, Big has the 10 bytes glyphs used by SCHIP and may be empty.
+type FontSet struct {
+	Name  string
+	Small []uint8
+	Big   []uint8
+}
+
+const (
+	SmallFontSize     = 0x10 * fontGlyphHeight
+	bigFontGlyphSize  = 10
+	bigFontDigitsSize = 10 * bigFontGlyphSize
+	bigFontHexSize    = 0x10 * bigFontGlyphSize
+)
+
+var (
+	// FontCOSMACVIP: font of the original interpreter on the COSMAC VIP
+	FontCOSMACVIP = FontSet{
+		Name: "vip",
+		Small: []uint8{
+			0xF0, 0x90, 0x90, 0x90, 0xF0, // 0
+			0x60, 0x20, 0x20, 0x20, 0x70, // 1
+			0xF0, 0x10, 0xF0, 0x80, 0xF0, // 2
+			0xF0, 0x10, 0xF0, 0x10, 0xF0, // 3
+			0xA0, 0xA0, 0xF0, 0x20, 0x20, // 4
+			0xF0, 0x80, 0xF0, 0x10, 0xF0, // 5
+			0xF0, 0x80, 0xF0, 0x90, 0xF0, // 6
+			0xF0, 0x10, 0x10, 0x10, 0x10, // 7
+			0xF0, 0x90, 0xF0, 0x90, 0xF0, // 8
+			0xF0, 0x90, 0xF0, 0x10, 0xF0, // 9
+			0xF0, 0x90, 0xF0, 0x90, 0x90, // A
+			0xF0, 0x50, 0x70, 0x50, 0xF0, // B
+			0xF0, 0x80, 0x80, 0x80, 0xF0, // C
+			0xF0, 0x50, 0x50, 0x50, 0xF0, // D
+			0xF0, 0x80, 0xF0, 0x80, 0xF0, // E
+			0xF0, 0x80, 0xF0, 0x80, 0x80, // F
+		},
+	}
+
+	// FontDream6800: font of the DREAM 6800, 3 pixels wide
+	FontDream6800 = FontSet{
+		Name: "dream6800",
+		Small: []uint8{
+			0xE0, 0xA0, 0xA0, 0xA0, 0xE0, // 0
+			0x40, 0x40, 0x40, 0x40, 0x40, // 1
+			0xE0, 0x20, 0xE0, 0x80, 0xE0, // 2
+			0xE0, 0x20, 0xE0, 0x20, 0xE0, // 3
+			0x80, 0xA0, 0xA0, 0xE0, 0x20, // 4
+			0xE0, 0x80, 0xE0, 0x20, 0xE0, // 5
+			0xE0, 0x80, 0xE0, 0xA0, 0xE0, // 6
+			0xE0, 0x20, 0x20, 0x20, 0x20, // 7
+			0xE0, 0xA0, 0xE0, 0xA0, 0xE0, // 8
+			0xE0, 0xA0, 0xE0, 0x20, 0xE0, // 9
+			0xE0, 0xA0, 0xE0, 0xA0, 0xA0, // A
+			0xC0, 0xA0, 0xE0, 0xA0, 0xC0, // B
+			0xE0, 0x80, 0x80, 0x80, 0xE0, // C
+			0xC0, 0xA0, 0xA0, 0xA0, 0xC0, // D
+			0xE0, 0x80, 0xE0, 0x80, 0xE0, // E
+			0xE0, 0x80, 0xC0, 0x80, 0x80, // F
+		},
+	}
+
+	// FontETI660: font of the ETI-660, 3 pixels wide
+	FontETI660 = FontSet{
+		Name: "eti660",
+		Small: []uint8{
+			0xE0, 0xA0, 0xA0, 0xA0, 0xE0, // 0
+			0x20, 0x20, 0x20, 0x20, 0x20, // 1
+			0xE0, 0x20, 0xE0, 0x80, 0xE0, // 2
+			0xE0, 0x20, 0xE0, 0x20, 0xE0, // 3
+			0xA0, 0xA0, 0xE0, 0x20, 0x20, // 4
+			0xE0, 0x80, 0xE0, 0x20, 0xE0, // 5
+			0xE0, 0x80, 0xE0, 0xA0, 0xE0, // 6
+			0xE0, 0x20, 0x20, 0x20, 0x20, // 7
+			0xE0, 0xA0, 0xE0, 0xA0, 0xE0, // 8
+			0xE0, 0xA0, 0xE0, 0x20, 0xE0, // 9
+			0xE0, 0xA0, 0xE0, 0xA0, 0xA0, // A
+			0x80, 0x80, 0xE0, 0xA0, 0xE0, // B
+			0xE0, 0x80, 0x80, 0x80, 0xE0, // C
+			0x20, 0x20, 0xE0, 0xA0, 0xE0, // D
+			0xE0, 0x80, 0xE0, 0x80, 0xE0, // E
+			0xE0, 0x80, 0xC0, 0x80, 0x80, // F
+		},
+	}
+
+	// FontSuperChip: fonts of SCHIP 1.1, the big font only has the digits 0 to 9
+	FontSuperChip = FontSet{
+		Name:  "schip",
+		Small: commonSmallFont,
+		Big: []uint8{
+			0x3C, 0x7E, 0xE7, 0xC3, 0xC3, 0xC3, 0xC3, 0xE7, 0x7E, 0x3C, // 0
+			0x18, 0x38, 0x58, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x3C, // 1
+			0x3E, 0x7F, 0xC3, 0x06, 0x0C, 0x18, 0x30, 0x60, 0xFF, 0xFF, // 2
+			0x3C, 0x7E, 0xC3, 0x03, 0x0E, 0x0E, 0x03, 0xC3, 0x7E, 0x3C, // 3
+			0x06, 0x0E, 0x1E, 0x36, 0x66, 0xC6, 0xFF, 0xFF, 0x06, 0x06, // 4
+			0xFF, 0xFF, 0xC0, 0xC0, 0xFC, 0xFE, 0x03, 0xC3, 0x7E, 0x3C, // 5
+			0x3E, 0x7C, 0xE0, 0xC0, 0xFC, 0xFE, 0xC3, 0xC3, 0x7E, 0x3C, // 6
+			0xFF, 0xFF, 0x03, 0x06, 0x0C, 0x18, 0x30, 0x60, 0x60, 0x60, // 7
+			0x3C, 0x7E, 0xC3, 0xC3, 0x7E, 0x7E, 0xC3, 0xC3, 0x7E, 0x3C, // 8
+			0x3C, 0x7E, 0xC3, 0xC3, 0x7F, 0x3F, 0x03, 0x03, 0x3E, 0x7C, // 9
+		},
+	}
+
+	// FontOcto: fonts of the Octo IDE, the big font has all the hex digits
+	FontOcto = FontSet{
+		Name:  "octo",
+		Small: commonSmallFont,
+		Big: []uint8{
+			0xFF, 0xFF, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, // 0
+			0x18, 0x78, 0x78, 0x18, 0x18, 0x18, 0x18, 0x18, 0xFF, 0xFF, // 1
+			0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, // 2
+			0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 3
+			0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0x03, 0x03, // 4
+			0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 5
+			0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, // 6
+			0xFF, 0xFF, 0x03, 0x03, 0x06, 0x0C, 0x18, 0x18, 0x18, 0x18, // 7
+			0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, // 8
+			0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 9
+			0x7E, 0xFF, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xC3, // A
+			0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, // B
+			0x3C, 0xFF, 0xC3, 0xC0, 0xC0, 0xC0, 0xC0, 0xC3, 0xFF, 0x3C, // C
+			0xFC, 0xFE, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFE, 0xFC, // D
+			0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, // E
+			0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xC0, 0xC0, // F
+		},
+	}
+
+	// DefaultFont: the font loaded when none is selected
+	DefaultFont = FontOcto
+
+	// FontSets: the built-in fonts by name
+	FontSets = map[string]FontSet{
+		FontCOSMACVIP.Name: FontCOSMACVIP,
+		FontDream6800.Name: FontDream6800,
+		FontETI660.Name:    FontETI660,
+		FontSuperChip.Name: FontSuperChip,
+		FontOcto.Name:      FontOcto,
+	}
+)
+
+// commonSmallFont: the 4x5 font used by most interpreters since CHIP-48
+var commonSmallFont = []uint8{
+	0xF0, 0x90, 0x90, 0x90, 0xF0, // 0
+	0x20, 0x60, 0x20, 0x20, 0x70, // 1
+	0xF0, 0x10, 0xF0, 0x80, 0xF0, // 2
+	0xF0, 0x10, 0xF0, 0x10, 0xF0, // 3
+	0x90, 0x90, 0xF0, 0x10, 0x10, // 4
+	0xF0, 0x80, 0xF0, 0x10, 0xF0, // 5
+	0xF0, 0x80, 0xF0, 0x90, 0xF0, // 6
+	0xF0, 0x10, 0x20, 0x40, 0x40, // 7
+	0xF0, 0x90, 0xF0, 0x90, 0xF0, // 8
+	0xF0, 0x90, 0xF0, 0x10, 0xF0, // 9
+	0xF0, 0x90, 0xF0, 0x90, 0x90, // A
+	0xE0, 0x90, 0xE0, 0x90, 0xE0, // B
+	0xF0, 0x80, 0x80, 0x80, 0xF0, // C
+	0xE0, 0x90, 0x90, 0x90, 0xE0, // D
+	0xF0, 0x80, 0xF0, 0x80, 0xF0, // E
+	0xF0, 0x80, 0xF0, 0x80, 0x80, // F
+}
+
+// FontSetNames: names of the built-in fonts, sorted
+func FontSetNames() []string {
+	names := make([]string, 0, len(FontSets))
+	for name := range FontSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseFont: reads a raw binary font, which is the 80 bytes of the small font optionally
+// followed by the 100 bytes of a SCHIP big font (0 to 9) or 160 bytes of a big font (0 to F)
+func ParseFont(name string, data []uint8) (FontSet, error) {
+	switch len(data) {
+	case SmallFontSize, SmallFontSize + bigFontDigitsSize, SmallFontSize + bigFontHexSize:
+	default:
+		return FontSet{}, fmt.Errorf("font %q has %d bytes, expected %d, %d or %d",
+			name, len(data), SmallFontSize, SmallFontSize+bigFontDigitsSize, SmallFontSize+bigFontHexSize)
+	}
+
+	font := FontSet{
+		Name:  name,
+		Small: append([]uint8{}, data[:SmallFontSize]...),
+	}
+	if len(data) > SmallFontSize {
+		font.Big = append([]uint8{}, data[SmallFontSize:]...)
+	}
+	return font, nil
+}
+
+// LoadFontFile: reads a raw binary font from a file, see ParseFont
+func LoadFontFile(path string) (FontSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FontSet{}, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ParseFont(name, data)
+}
+
+// SelectFont: sets the font and the address it's loaded at, and loads it into memory.
+// The font has to end before the address programs are loaded at on the platform, or
+// loading it would overwrite them.
+func (c *Chip8) SelectFont(font FontSet, addr uint16) error {
+	if err := checkFont(font, addr, c.Platform); err != nil {
+		return err
+	}
+	c.Font = font
+	c.FontAddress = addr
+	c.LoadFonts()
+	return nil
+}
+
+// checkFont: an error if the font doesn't fit in memory at the address or overlaps the
+// programs of the platform
+func checkFont(font FontSet, addr uint16, platform Platform) error {
+	if len(font.Small) != SmallFontSize {
+		return fmt.Errorf("font %q has a small font of %d bytes, expected %d", font.Name, len(font.Small), SmallFontSize)
+	}
+	end := int(addr) + len(font.Small) + len(font.Big)
+	if end > MemorySize {
+		return fmt.Errorf("font %q doesn't fit in memory at 0x%03x", font.Name, addr)
+	}
+	if platform.MemorySize == 0 {
+		platform = PlatformCHIP8
+	}
+	if end > int(platform.LoadAddress) {
+		return fmt.Errorf("font %q at 0x%03x-0x%03x overlaps the programs, which %s loads at 0x%03x",
+			font.Name, addr, end-1, platform.Name, platform.LoadAddress)
+	}
+	return nil
+}
+
+// BigFontAddress: where the big font starts, right after the small font
+func (c *Chip8) BigFontAddress() uint16 {
+	return c.FontAddress + SmallFontSize
+}
@@ -0,0 +1,69 @@
+package chip8
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFonts(t *testing.T) {
+	t.Run("Every built-in font should have 16 small glyphs and a valid big font", func(t *testing.T) {
+		for _, name := range FontSetNames() {
+			font := FontSets[name]
+			assert.Len(t, font.Small, SmallFontSize, "Font %s", name)
+			assert.Contains(t, []int{0, bigFontDigitsSize, bigFontHexSize}, len(font.Big), "Font %s", name)
+		}
+	})
+
+	t.Run("SelectFont should load the font at the address and Fx29 should point to it", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.SelectFont(FontETI660, 0x100))
+		assert.Equal(t, FontETI660.Small[:5], c.CurrState.Memory[0x100:0x105], "Font should be loaded at the address")
+
+		c.CurrState.V[0x1] = 0xB
+		newState := c.ExecuteOpcode(0xF129)
+		assert.Equal(t, uint16(0x100+5*0xB), newState.I, "I should point to the glyph of the selected font")
+		assert.Equal(t, []uint8{0x80, 0x80, 0xE0, 0xA0, 0xE0}, newState.Memory[newState.I:newState.I+5])
+	})
+
+	t.Run("SelectFont should load the big font right after the small font", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.SelectFont(FontSuperChip, FontsStartAddress))
+		addr := c.BigFontAddress()
+		assert.Equal(t, FontsStartAddress+SmallFontSize, addr)
+		assert.Equal(t, FontSuperChip.Big, c.CurrState.Memory[addr:addr+bigFontDigitsSize])
+	})
+
+	t.Run("SelectFont should refuse fonts that don't fit in memory", func(t *testing.T) {
+		c := New()
+		assert.Error(t, c.SelectFont(FontOcto, 0xFC0))
+		assert.Error(t, c.SelectFont(FontSet{Name: "broken", Small: []uint8{0xF0}}, FontsStartAddress))
+	})
+
+	t.Run("SelectFont should refuse addresses where the font would overwrite the program", func(t *testing.T) {
+		c := New()
+		assert.Error(t, c.SelectFont(FontCOSMACVIP, ProgramStartAddress))
+		assert.Error(t, c.SelectFont(FontSuperChip, ProgramStartAddress-SmallFontSize), "The big font should fit too")
+		assert.NoError(t, c.SelectFont(FontCOSMACVIP, ProgramStartAddress-SmallFontSize))
+
+		c.Platform = PlatformETI660
+		assert.NoError(t, c.SelectFont(FontSuperChip, 0x400), "ETI-660 programs start at 0x600")
+	})
+
+	t.Run("LoadFontFile should read custom fonts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "custom.bin")
+		data := append(append([]uint8{}, FontCOSMACVIP.Small...), FontSuperChip.Big...)
+		assert.NoError(t, os.WriteFile(path, data, 0644))
+
+		font, err := LoadFontFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "custom", font.Name)
+		assert.Equal(t, FontCOSMACVIP.Small, font.Small)
+		assert.Equal(t, FontSuperChip.Big, font.Big)
+
+		_, err = ParseFont("short", data[:10])
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,107 @@
+package chip8
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// fuzzTicks: how many instructions every fuzzed program runs for
+const fuzzTicks = 2000
+
+// addROMSeeds: seeds the corpus with the bundled ROMs and programs that hit the edges
+// of memory and of the stack
+func addROMSeeds(f *testing.F, add func(rom []uint8)) {
+	paths, _ := filepath.Glob("../roms/*.ch8")
+	for _, path := range paths {
+		if data, err := ioutil.ReadFile(path); err == nil {
+			add(data)
+		}
+	}
+	add([]uint8{0x00, 0xEE})                         // RET with an empty stack
+	add([]uint8{0x22, 0x00})                         // CALL itself until the stack overflows
+	add([]uint8{0xAF, 0xFF, 0xFF, 0x55, 0xFF, 0x65}) // Fx55 and Fx65 past the end of memory
+	add([]uint8{0x6F, 0xFF, 0xBF, 0xFF})             // Bnnn past the end of memory
+	add([]uint8{0x1F, 0xFE})                         // JP to the last opcode of memory
+	add([]uint8{0xAF, 0xFF, 0xD0, 0x1F})             // DRW from the end of memory
+}
+
+// checkInvariants: what must hold after every tick, whatever the program does
+func checkInvariants(t *testing.T, c *Chip8) {
+	s := &c.CurrState
+	if c.Fault == nil && int(s.PC) >= MemorySize {
+		t.Fatalf("PC 0x%04x is out of memory without a fault", s.PC)
+	}
+	if int(s.SP) > StackSize {
+		t.Fatalf("SP %d is out of the stack", s.SP)
+	}
+	if len(c.History()) > c.HistoryLimit {
+		t.Fatalf("%d states on the history, the limit is %d", len(c.History()), c.HistoryLimit)
+	}
+	s.Opcode()
+}
+
+// FuzzTick: runs random programs with random input and checks the machine stays consistent
+func FuzzTick(f *testing.F) {
+	addROMSeeds(f, func(rom []uint8) {
+		f.Add(rom, []uint8{0x00, 0x15, 0xFF}, uint8(0))
+	})
+	f.Fuzz(func(t *testing.T, rom []uint8, input []uint8, options uint8) {
+		c := New()
+		c.Platform = PlatformSuperChip
+		c.AddressMode = AddressMode(options & 0x1)
+		c.Quirks = Quirks{
+			Shift: options&0x02 != 0, Jump: options&0x04 != 0, Wrap: options&0x08 != 0,
+			CountClippedRows: options&0x10 != 0, DisplayWait: options&0x20 != 0,
+		}
+		c.Timing = TimingMode(options >> 6 & 0x1)
+		c.HistoryLimit = int(options >> 7)
+		if err := c.LoadGame(rom); err != nil {
+			return
+		}
+
+		for i := 0; i < fuzzTicks; i++ {
+			// Every byte of input is a key held for a while, released when its highest bit is set
+			if len(input) > 0 && i%64 == 0 {
+				key := input[i/64%len(input)]
+				if key&0x80 != 0 {
+					c.ReleaseKey(key & 0x0F)
+				} else {
+					c.PressKey(key & 0x0F)
+				}
+			}
+			c.Tick(0)
+			if i%8 == 7 {
+				c.EndFrame()
+			}
+			checkInvariants(t, c)
+		}
+	})
+}
+
+// FuzzExecuteOpcode: any opcode on any registers leaves the current state alone
+func FuzzExecuteOpcode(f *testing.F) {
+	for _, opcode := range []uint16{0x00E0, 0x00EE, 0x2FFF, 0x8FF6, 0xBFFF, 0xDFFF, 0xF00A, 0xFF33, 0xFF55, 0xFF65} {
+		f.Add(opcode, uint16(0xFFF), uint8(0xFF), uint8(StackSize))
+	}
+	f.Fuzz(func(t *testing.T, opcode uint16, i uint16, v uint8, sp uint8) {
+		c := New()
+		c.CurrState.PC = ProgramStartAddress
+		c.CurrState.I = i
+		c.CurrState.SP = sp % (StackSize + 1)
+		for x := range c.CurrState.V {
+			c.CurrState.V[x] = v + uint8(x)
+		}
+		before := c.CurrState
+
+		in := Decode(opcode)
+		if in.Opcode != opcode || in.Op >= operationCount {
+			t.Fatalf("Decode(%04X) = %+v", opcode, in)
+		}
+		Disassemble(opcode)
+		c.ExecuteOpcode(opcode)
+		if c.CurrState != before || c.Fault != nil {
+			t.Fatalf("ExecuteOpcode(%04X) changed the current state or faulted", opcode)
+		}
+	})
+}
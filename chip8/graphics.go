@@ -14,17 +14,40 @@ var Keyboard2Chip8 = map[sdl.Keycode]uint8{
 	sdl.K_z: 0xA, sdl.K_x: 0x0, sdl.K_c: 0xB, sdl.K_v: 0xF,
 }
 
+// Keyboard2ROMKey: extra keys bound to the key names of the ROM database, so games
+// can be played with the arrows when the database knows their controls
+var Keyboard2ROMKey = map[sdl.Keycode]string{
+	sdl.K_UP: "up", sdl.K_DOWN: "down", sdl.K_LEFT: "left", sdl.K_RIGHT: "right",
+	sdl.K_SPACE: "a", sdl.K_RETURN: "b",
+}
+
 type SDLGraphics struct {
 	Title  string
 	Width  int
 	Height int
+	// ROMDir is the directory listed by the launcher
+	ROMDir string
+	// UseROMDatabase applies the ROM database settings to the ROMs picked on the launcher
+	UseROMDatabase bool
 
 	running  bool
+	window   *sdl.Window
 	renderer *sdl.Renderer
 
-	c8 *Chip8
+	c8       *Chip8
+	defaults machineDefaults
+	keymap   map[sdl.Keycode]uint8
+	padmap   map[uint8]uint8
+
+	display        *Display
+	displayTexture *sdl.Texture
 
-	font *ttf.Font
+	font        *ttf.Font
+	hud         hud
+	memoryPanel memoryPanel
+	launcher    launcher
+
+	controller *Controller
 }
 
 func (g *SDLGraphics) Run() error {
@@ -32,10 +55,13 @@ func (g *SDLGraphics) Run() error {
 	if err := g.setup(); err != nil {
 		return err
 	}
+	g.applyROMInfo()
+	g.memoryPanel.viewer.Forget()
+	if g.c8.ROM == nil {
+		g.openLauncher()
+	}
 
-	const FPS = 500.0
-	const secsPerUpdate = 1 / FPS
-	var current, elapsed, lag float64
+	var current, elapsed float64
 	previous := float64(sdl.GetTicks()) * 0.001
 
 	for g.running {
@@ -47,75 +73,59 @@ func (g *SDLGraphics) Run() error {
 			continue
 		}
 
-		lag += elapsed
-
 		// Input/Events
 		g.handleEvents()
 
 		// Update
-		for lag >= secsPerUpdate {
-			g.c8.Tick(secsPerUpdate)
-			lag -= secsPerUpdate
+		if !g.launcher.visible {
+			g.controller.Update(elapsed)
 		}
 
 		// Draw
 		g.renderer.SetDrawColor(0, 0, 0, 0)
 		g.renderer.Clear()
 
+		if g.launcher.visible {
+			if err := g.drawLauncher(); err != nil {
+				return err
+			}
+			g.renderer.Present()
+			g.evictText()
+			g.hud.countFrame(current)
+			continue
+		}
+
 		pivotX, pivotY, pivotW, pivotH := 50, 50, ScreenWidth*4, ScreenHeight*4
 		borderSize := 10
 
 		g.drawBackground(pivotX, pivotY, pivotW, pivotH, borderSize)
-		g.drawChip8(pivotX, pivotY, pivotW, pivotH)
+		if err := g.drawChip8(pivotX, pivotY, pivotW, pivotH); err != nil {
+			return err
+		}
 
-		err := g.text("teste", 100, 100)
-		if err != nil {
+		if err := g.drawHUD(pivotX+pivotW+30, pivotY-10); err != nil {
+			return err
+		}
+		if err := g.drawMemoryPanel(pivotX-borderSize, pivotY+pivotH+borderSize*2); err != nil {
 			return err
 		}
 
 		g.renderer.Present()
-	}
-
-	return nil
-}
-
-func (g *SDLGraphics) text(msg string, x, y int) error {
-	t, err := g.font.RenderUTF8Blended(msg, sdl.Color{
-		R: 255,
-		G: 255,
-		B: 255,
-		A: 255,
-	})
-	if err != nil {
-		return err
-	}
-
-	texture, err := g.renderer.CreateTextureFromSurface(t)
-	if err != nil {
-		return err
-	}
-
-	err = g.renderer.Copy(texture, nil,
-
-		&sdl.Rect{
-			X: int32(x),
-			Y: int32(y),
-			W: 50,
-			H: 50,
-		})
-	if err != nil {
-		return err
+		g.evictText()
+		g.hud.countFrame(current)
 	}
 
 	return nil
 }
 
 func (g *SDLGraphics) selectMainPalette() {
-	g.renderer.SetDrawColor(194, 62, 128, 255)
+	fg := g.display.Foreground
+	g.renderer.SetDrawColor(fg.R, fg.G, fg.B, fg.A)
 }
 
 func (g *SDLGraphics) selectBackgroundPalette() {
-	g.renderer.SetDrawColor(0, 0, 0, 0)
+	bg := g.display.Background
+	g.renderer.SetDrawColor(bg.R, bg.G, bg.B, bg.A)
 }
 
 func (g *SDLGraphics) drawBackground(pivotX, pivotY, pivotW, pivotH, borderSize int) {
@@ -138,25 +148,17 @@ func (g *SDLGraphics) drawBackground(pivotX, pivotY, pivotW, pivotH, borderSize
 }
 
 func (g *SDLGraphics) drawChip8(pivotX, pivotY, pivotW, pivotH int) error {
-	g.selectMainPalette()
-	pixelWidth := int(pivotW / ScreenWidth)
-	pixelHeight := int(pivotH / ScreenHeight)
-
-	for y := 0; y < ScreenHeight; y++ {
-		for x := 0; x < ScreenWidth; x++ {
-			if g.c8.CurrState.GetPixel(uint8(x), uint8(y)) {
-				rect := &sdl.Rect{
-					X: int32(pivotX + x*pixelWidth),
-					Y: int32(pivotY + y*pixelHeight),
-					W: int32(pixelWidth),
-					H: int32(pixelHeight),
-				}
-				g.renderer.FillRect(rect)
-			}
-		}
+	frame := g.display.Render()
+	if err := g.displayTexture.Update(nil, frame.Pix, frame.Stride); err != nil {
+		return err
 	}
 
-	return nil
+	return g.renderer.Copy(g.displayTexture, nil, &sdl.Rect{
+		X: int32(pivotX),
+		Y: int32(pivotY),
+		W: int32(pivotW),
+		H: int32(pivotH),
+	})
 }
 
 func (g *SDLGraphics) setup() error {
@@ -185,6 +187,7 @@ func (g *SDLGraphics) setup() error {
 	if err != nil {
 		return err
 	}
+	g.window = window
 
 	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
 	if err != nil {
@@ -193,6 +196,18 @@ func (g *SDLGraphics) setup() error {
 
 	// renderer.SetLogicalSize(int32(ScreenWidth), int32(ScreenHeight))
 	g.renderer = renderer
+
+	frame := g.display.Render()
+	texture, err := renderer.CreateTexture(
+		sdl.PIXELFORMAT_RGBA32,
+		sdl.TEXTUREACCESS_STREAMING,
+		int32(frame.Bounds().Dx()),
+		int32(frame.Bounds().Dy()),
+	)
+	if err != nil {
+		return err
+	}
+	g.displayTexture = texture
 	return nil
 }
 
@@ -203,22 +218,136 @@ func (g *SDLGraphics) handleEvents() {
 			fmt.Println("Quit")
 			g.running = false
 		case *sdl.KeyboardEvent:
-			key := Keyboard2Chip8[t.Keysym.Sym]
+			if t.Type == sdl.KEYDOWN && g.handleHotkey(t.Keysym.Sym) {
+				continue
+			}
+			key, ok := g.keymap[t.Keysym.Sym]
+			if !ok {
+				continue
+			}
 			if t.Type == sdl.KEYDOWN {
 				g.c8.PressKey(key)
 			} else {
 				g.c8.ReleaseKey(key)
 			}
+		case *sdl.ControllerDeviceEvent:
+			if t.Type == sdl.CONTROLLERDEVICEADDED {
+				sdl.GameControllerOpen(int(t.Which))
+			}
+		case *sdl.ControllerButtonEvent:
+			if t.Type == sdl.CONTROLLERBUTTONDOWN && g.handleLauncherButton(t.Button) {
+				continue
+			}
+			key, ok := g.padmap[t.Button]
+			if !ok {
+				continue
+			}
+			if t.Type == sdl.CONTROLLERBUTTONDOWN {
+				g.c8.PressKey(key)
+			} else {
+				g.c8.ReleaseKey(key)
+			}
+		}
+	}
+}
+
+// handleHotkey: returns true if the key belongs to the frontend instead of the chip-8 keyboard
+func (g *SDLGraphics) handleHotkey(key sdl.Keycode) bool {
+	return g.handleLauncherKey(key) || g.handleControlKey(key) || g.toggleFilter(key) || g.hud.togglePanel(key) || g.handleMemoryPanelKey(key)
+}
+
+// toggleFilter: F5 to F8 turn the display filters on and off
+func (g *SDLGraphics) toggleFilter(key sdl.Keycode) bool {
+	filters := &g.display.Filters
+	switch key {
+	case sdl.K_F5:
+		if filters.Phosphor > 0 {
+			filters.Phosphor = 0
+		} else {
+			filters.Phosphor = 0.6
+		}
+	case sdl.K_F6:
+		filters.AntiFlicker = !filters.AntiFlicker
+	case sdl.K_F7:
+		if filters.Scanlines > 0 {
+			filters.Scanlines = 0
+		} else {
+			filters.Scanlines = 0.5
 		}
+	case sdl.K_F8:
+		filters.PixelGrid = !filters.PixelGrid
+	default:
+		return false
 	}
+	return true
 }
 
+// applyROMInfo: uses the title, keys and palette recommended by the ROM database for the loaded ROM
+func (g *SDLGraphics) applyROMInfo() {
+	g.keymap = make(map[sdl.Keycode]uint8, len(Keyboard2Chip8)+len(Keyboard2ROMKey))
+	for sym, key := range Keyboard2Chip8 {
+		g.keymap[sym] = key
+	}
+	g.padmap = make(map[uint8]uint8, len(Gamepad2ROMKey))
+
+	if g.c8.ROM == nil || g.c8.ROM.Info == nil {
+		g.window.SetTitle(g.Title)
+		return
+	}
+	info := g.c8.ROM.Info
+
+	g.window.SetTitle(fmt.Sprintf("%s - %s", g.Title, info.Title))
+	for sym, name := range Keyboard2ROMKey {
+		if key, ok := info.Keys[name]; ok {
+			g.keymap[sym] = key
+		}
+	}
+	for button, name := range Gamepad2ROMKey {
+		if key, ok := info.Keys[name]; ok {
+			g.padmap[button] = key
+		}
+	}
+	if len(info.Palette) >= 2 {
+		g.display.Background = info.Palette[0]
+		g.display.Foreground = info.Palette[1]
+	}
+}
+
+// frame: called by the controller after every emulated frame
+func (g *SDLGraphics) frame(ticks int) {
+	g.display.Push(g.c8.CurrState.Graphics)
+	g.hud.countCycles(ticks)
+	g.memoryPanel.viewer.Update()
+}
+
+// NewGraphicsSDL: the machine settings at this point are the ones used for ROMs
+// the database doesn't know when switching games on the launcher
 func NewGraphicsSDL(c8 *Chip8) *SDLGraphics {
-	return &SDLGraphics{
-		Title:   "Chip-8",
-		Width:   600,
-		Height:  400,
+	display := NewDisplay(4)
+	g := &SDLGraphics{
+		Title:          "Chip-8",
+		Width:          600,
+		Height:         400,
+		ROMDir:         "roms",
+		UseROMDatabase: true,
+
 		running: true,
 		c8:      c8,
+		defaults: machineDefaults{
+			platform:    c8.Platform,
+			quirks:      c8.Quirks,
+			tickRate:    c8.TickRate,
+			font:        c8.Font,
+			fontAddress: c8.FontAddress,
+			foreground:  display.Foreground,
+			background:  display.Background,
+		},
+		display: display,
+		hud:     newHUD(),
+
+		memoryPanel: newMemoryPanel(c8),
+		controller:  NewController(c8),
 	}
+	g.controller.Frame = g.frame
+	return g
 }
@@ -0,0 +1,247 @@
+package chip8
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// MemoryUse: the ways an address was used, as a set of bits
+type MemoryUse uint8
+
+const (
+	// MemoryExecuted: fetched as part of an instruction
+	MemoryExecuted MemoryUse = 1 << iota
+	// MemorySpriteRead: read by Dxyn as a row of a sprite
+	MemorySpriteRead
+	// MemoryDataRead: read into the registers by Fx65
+	MemoryDataRead
+	// MemoryBCDWritten: written with the digits of a register by Fx33
+	MemoryBCDWritten
+	// MemoryRegistersWritten: written with the registers by Fx55
+	MemoryRegistersWritten
+	// MemoryOtherWritten: written from outside the program, like a debugger through WriteMemory
+	MemoryOtherWritten
+
+	memoryUseCount = iota
+)
+
+// MemoryUses: every use, in the order their colours win on the heatmap when an address
+// had more than one use in the same row: writes, then code, then sprites, then data
+var MemoryUses = []MemoryUse{MemoryRegistersWritten, MemoryBCDWritten, MemoryOtherWritten, MemoryExecuted, MemorySpriteRead, MemoryDataRead}
+
+var memoryUseNames = [memoryUseCount]string{"code", "sprite", "data", "Fx33 write", "Fx55 write", "other write"}
+
+// memoryUseColors: the colours of the heatmap, at full brightness
+var memoryUseColors = [memoryUseCount]color.RGBA{
+	{R: 80, G: 220, B: 80, A: 255},
+	{R: 80, G: 160, B: 255, A: 255},
+	{R: 220, G: 220, B: 80, A: 255},
+	{R: 255, G: 150, B: 40, A: 255},
+	{R: 255, G: 60, B: 60, A: 255},
+	{R: 220, G: 80, B: 220, A: 255},
+}
+
+func (u MemoryUse) index() int {
+	for i := 0; i < memoryUseCount; i++ {
+		if u == 1<<i {
+			return i
+		}
+	}
+	return -1
+}
+
+// String: the names of the uses, separated by commas
+func (u MemoryUse) String() string {
+	if u == 0 {
+		return "unused"
+	}
+	var names []string
+	for i, name := range memoryUseNames {
+		if u&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Color: the colour of a single use on the heatmap
+func (u MemoryUse) Color() color.RGBA {
+	if i := u.index(); i >= 0 {
+		return memoryUseColors[i]
+	}
+	return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+}
+
+// heatmapRow: the accesses to every address over a stretch of time
+type heatmapRow struct {
+	accesses [MemorySize]uint16
+	uses     [MemorySize]MemoryUse
+}
+
+// MemoryHeatmap records how a running Chip8 uses its memory over time. The accesses are
+// grouped in rows, a new one starts on every call to NextRow, so rendered as an image
+// time goes from top to bottom and the addresses from left to right.
+type MemoryHeatmap struct {
+	rows   []*heatmapRow
+	totals [memoryUseCount][MemorySize]int64
+	// op is the operation of the instruction fetched on the tick numbered opTick, which tells
+	// the writes apart. Writes on any other tick come from outside the program.
+	op     Operation
+	opTick int64
+}
+
+// RecordMemory: starts recording the memory accesses of the Chip8. Watching the reads makes it
+// run on the interpreter, see Run.
+func RecordMemory(c8 *Chip8) *MemoryHeatmap {
+	h := &MemoryHeatmap{rows: []*heatmapRow{{}}, opTick: -1}
+
+	previousFetched := c8.Hooks.InstructionFetched
+	c8.Hooks.InstructionFetched = func(pc uint16, in Instruction) {
+		if previousFetched != nil {
+			previousFetched(pc, in)
+		}
+		h.op, h.opTick = in.Op, c8.TickCount
+	}
+	previousRead := c8.Hooks.MemoryRead
+	c8.Hooks.MemoryRead = func(addr uint16, value uint8, access Access) {
+		if previousRead != nil {
+			previousRead(addr, value, access)
+		}
+		switch access {
+		case AccessFetch:
+			h.add(addr, MemoryExecuted)
+		case AccessSprite:
+			h.add(addr, MemorySpriteRead)
+		default:
+			h.add(addr, MemoryDataRead)
+		}
+	}
+	previousWrite := c8.Hooks.MemoryWrite
+	c8.Hooks.MemoryWrite = func(addr uint16, value uint8) {
+		if previousWrite != nil {
+			previousWrite(addr, value)
+		}
+		switch {
+		case h.opTick != c8.TickCount:
+			h.add(addr, MemoryOtherWritten)
+		case h.op == OpLDB:
+			h.add(addr, MemoryBCDWritten)
+		case h.op == OpLDIVx:
+			h.add(addr, MemoryRegistersWritten)
+		default:
+			h.add(addr, MemoryOtherWritten)
+		}
+	}
+	return h
+}
+
+func (h *MemoryHeatmap) add(addr uint16, use MemoryUse) {
+	addr &= AddressMask
+	row := h.rows[len(h.rows)-1]
+	if row.accesses[addr] < math.MaxUint16 {
+		row.accesses[addr]++
+	}
+	row.uses[addr] |= use
+	h.totals[use.index()][addr]++
+}
+
+// NextRow: starts a new row, the accesses from now on go to it
+func (h *MemoryHeatmap) NextRow() {
+	h.rows = append(h.rows, &heatmapRow{})
+}
+
+// Rows: how many rows were recorded
+func (h *MemoryHeatmap) Rows() int {
+	return len(h.rows)
+}
+
+// Uses: every way the address was used so far
+func (h *MemoryHeatmap) Uses(addr uint16) MemoryUse {
+	var uses MemoryUse
+	for i := range h.totals {
+		if h.totals[i][addr&AddressMask] > 0 {
+			uses |= 1 << i
+		}
+	}
+	return uses
+}
+
+// Count: how many times the address was used that way so far
+func (h *MemoryHeatmap) Count(addr uint16, use MemoryUse) int64 {
+	if i := use.index(); i >= 0 {
+		return h.totals[i][addr&AddressMask]
+	}
+	return 0
+}
+
+// Render: draws the rows of the addresses from start to end, scale by scale pixels per address.
+// The colour is the one of the use that wins, see MemoryUses, and brighter for addresses
+// accessed more in the row. Addresses left alone are dark grey.
+func (h *MemoryHeatmap) Render(start, end uint16, scale int) *image.RGBA {
+	if scale < 1 {
+		scale = 1
+	}
+	if int(end) > MemorySize {
+		end = MemorySize
+	}
+	if end < start {
+		end = start
+	}
+	width := int(end - start)
+	img := image.NewRGBA(image.Rect(0, 0, width*scale, len(h.rows)*scale))
+
+	most := uint16(1)
+	for _, row := range h.rows {
+		for addr := start; addr < end; addr++ {
+			if row.accesses[addr] > most {
+				most = row.accesses[addr]
+			}
+		}
+	}
+
+	background := color.RGBA{R: 40, G: 40, B: 40, A: 255}
+	for y, row := range h.rows {
+		for x := 0; x < width; x++ {
+			addr := int(start) + x
+			pixel := background
+			if row.accesses[addr] > 0 {
+				// the logarithm keeps the loops of the code from washing out everything else
+				heat := math.Log1p(float64(row.accesses[addr])) / math.Log1p(float64(most))
+				pixel = shade(winningUse(row.uses[addr]).Color(), background, 0.35+0.65*heat)
+			}
+			for sy := 0; sy < scale; sy++ {
+				for sx := 0; sx < scale; sx++ {
+					img.SetRGBA(x*scale+sx, y*scale+sy, pixel)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG: exports the heatmap of the addresses from start to end as a PNG
+func (h *MemoryHeatmap) WritePNG(w io.Writer, start, end uint16, scale int) error {
+	return png.Encode(w, h.Render(start, end, scale))
+}
+
+// winningUse: the use whose colour an address with these uses is drawn with
+func winningUse(uses MemoryUse) MemoryUse {
+	for _, use := range MemoryUses {
+		if uses&use != 0 {
+			return use
+		}
+	}
+	return 0
+}
+
+// shade: mixes the colour with the background, all of the colour at 1
+func shade(c, background color.RGBA, amount float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(b) + (float64(a)-float64(b))*amount)
+	}
+	return color.RGBA{R: mix(c.R, background.R), G: mix(c.G, background.G), B: mix(c.B, background.B), A: 255}
+}
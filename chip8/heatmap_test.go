@@ -0,0 +1,104 @@
+package chip8
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryHeatmap(t *testing.T) {
+	c := New()
+	c.Quirks = Quirks{}
+	c.LoadGame([]uint8{
+		0xA2, 0x40, // 200: LD I, 0x240
+		0xD0, 0x11, // 202: DRW V0, V1, 1
+		0xA2, 0x20, // 204: LD I, 0x220
+		0xF0, 0x33, // 206: LD B, V0
+		0xF1, 0x55, // 208: LD [I], V1
+		0xA2, 0x30, // 20A: LD I, 0x230
+		0xF0, 0x65, // 20C: LD V0, [I]
+		0x12, 0x0E, // 20E: JP 0x20E
+	})
+	h := RecordMemory(c)
+	for i := 0; i < 8; i++ {
+		c.Tick(0)
+	}
+	h.NextRow()
+	c.Tick(0)
+	c.Tick(0)
+
+	t.Run("RecordMemory should tell apart code, sprites, data and the writes of Fx33 and Fx55", func(t *testing.T) {
+		assert.Equal(t, MemoryExecuted, h.Uses(0x200))
+		assert.Equal(t, MemoryExecuted, h.Uses(0x201))
+		assert.Equal(t, MemorySpriteRead, h.Uses(0x240))
+		assert.Equal(t, MemoryBCDWritten|MemoryRegistersWritten, h.Uses(0x220))
+		assert.Equal(t, MemoryBCDWritten, h.Uses(0x222))
+		assert.Equal(t, MemoryDataRead, h.Uses(0x230))
+		assert.Equal(t, MemoryUse(0), h.Uses(0x212))
+		assert.Equal(t, "Fx33 write, Fx55 write", h.Uses(0x220).String())
+	})
+
+	t.Run("RecordMemory should count the accesses", func(t *testing.T) {
+		assert.Equal(t, int64(3), h.Count(0x20E, MemoryExecuted))
+		assert.Equal(t, int64(1), h.Count(0x221, MemoryRegistersWritten))
+		assert.Equal(t, int64(0), h.Count(0x221, MemoryExecuted))
+		assert.Equal(t, 2, h.Rows())
+	})
+
+	t.Run("Render should draw a row per call to NextRow with the colour of the use", func(t *testing.T) {
+		img := h.Render(0x200, 0x250, 2)
+		assert.Equal(t, 0xA0, img.Bounds().Dx())
+		assert.Equal(t, 4, img.Bounds().Dy())
+
+		background := color.RGBA{R: 40, G: 40, B: 40, A: 255}
+		at := func(addr uint16, row int) color.RGBA {
+			return img.RGBAAt(int(addr-0x200)*2+1, row*2+1)
+		}
+		assert.Equal(t, background, at(0x212, 0))
+		assert.Equal(t, background, at(0x200, 1), "Should leave the code that stopped running dark")
+		code, sprite, written := at(0x20E, 1), at(0x240, 0), at(0x220, 0)
+		assert.True(t, code.G > code.R && code.G > code.B, "Should draw code in green, got %v", code)
+		assert.True(t, sprite.B > sprite.R && sprite.B > sprite.G, "Should draw sprites in blue, got %v", sprite)
+		assert.True(t, written.R > written.G && written.R > written.B, "Should draw Fx55 writes in red, got %v", written)
+	})
+
+	t.Run("WritePNG should encode the heatmap", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, h.WritePNG(&b, 0x200, 0x250, 1))
+		img, err := png.Decode(&b)
+		assert.NoError(t, err)
+		assert.Equal(t, 0x50, img.Bounds().Dx())
+	})
+
+	t.Run("RecordMemory should keep the hooks that were set", func(t *testing.T) {
+		c := New()
+		c.LoadGame([]uint8{0xF0, 0x55})
+		writes := 0
+		c.Hooks.MemoryWrite = func(addr uint16, value uint8) {
+			writes++
+		}
+		h := RecordMemory(c)
+		c.Tick(0)
+		assert.Equal(t, 1, writes)
+		assert.Equal(t, MemoryRegistersWritten, h.Uses(0x000))
+	})
+
+	t.Run("RecordMemory should only blame Fx33 and Fx55 for the writes made while they run", func(t *testing.T) {
+		c := New()
+		c.LoadGame([]uint8{0xF0, 0x55, 0x00, 0xE0})
+		h := RecordMemory(c)
+		c.WriteMemory(0x300, 0x01)
+		c.Tick(0)
+		c.WriteMemory(0x301, 0x01)
+		c.Tick(0)
+		c.WriteMemory(0x302, 0x01)
+
+		assert.Equal(t, MemoryRegistersWritten, h.Uses(0x000))
+		for addr := uint16(0x300); addr <= 0x302; addr++ {
+			assert.Equal(t, MemoryOtherWritten, h.Uses(addr), "0x%03X was written from outside the program", addr)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package chip8
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type HUDPanel int
+
+const (
+	HUDRegisters HUDPanel = iota
+	HUDStack
+	HUDInstruction
+	HUDPerformance
+	hudPanelCount
+)
+
+// Keyboard2HUDPanel: function keys that show and hide each HUD panel
+var Keyboard2HUDPanel = map[sdl.Keycode]HUDPanel{
+	sdl.K_F1: HUDRegisters,
+	sdl.K_F2: HUDStack,
+	sdl.K_F3: HUDInstruction,
+	sdl.K_F4: HUDPerformance,
+}
+
+type hud struct {
+	visible [hudPanelCount]bool
+
+	textures map[string]*cachedText
+
+	fps        int
+	frameCount int
+	fpsSince   float64
+
+	cyclesPerFrame int
+}
+
+type cachedText struct {
+	texture *sdl.Texture
+	w, h    int32
+	used    bool
+}
+
+func newHUD() hud {
+	h := hud{textures: map[string]*cachedText{}}
+	for panel := range h.visible {
+		h.visible[panel] = true
+	}
+	return h
+}
+
+// togglePanel: shows or hides the panel bound to the key, returns false if the key isn't bound to any panel
+func (h *hud) togglePanel(key sdl.Keycode) bool {
+	panel, ok := Keyboard2HUDPanel[key]
+	if !ok {
+		return false
+	}
+	h.visible[panel] = !h.visible[panel]
+	return true
+}
+
+// countFrame: updates the FPS counter, should be called once per rendered frame
+func (h *hud) countFrame(now float64) {
+	h.frameCount++
+	if now-h.fpsSince >= 1 {
+		h.fps = h.frameCount
+		h.frameCount = 0
+		h.fpsSince = now
+	}
+}
+
+// countCycles: keeps how many instructions ran during the last 60Hz frame
+func (h *hud) countCycles(ticks int) {
+	h.cyclesPerFrame = ticks
+}
+
+func (g *SDLGraphics) drawHUD(x, y int) error {
+	s := &g.c8.CurrState
+
+	if g.hud.visible[HUDRegisters] {
+		for row := 0; row < 4; row++ {
+			line := ""
+			for col := 0; col < 4; col++ {
+				reg := row*4 + col
+				line += fmt.Sprintf("V%X %02X  ", reg, s.V[reg])
+			}
+			if err := g.textLine(line, x, &y); err != nil {
+				return err
+			}
+		}
+		line := fmt.Sprintf("I %03X  PC %03X  SP %X", s.I, s.PC, s.SP)
+		if err := g.textLine(line, x, &y); err != nil {
+			return err
+		}
+		y += hudPanelSpacing
+	}
+
+	if g.hud.visible[HUDStack] {
+		if err := g.textLine("Stack", x, &y); err != nil {
+			return err
+		}
+		for level := int(s.SP) - 1; level >= 0 && level < len(s.Stack); level-- {
+			line := fmt.Sprintf(" %X: %03X", level, s.Stack[level])
+			if err := g.textLine(line, x, &y); err != nil {
+				return err
+			}
+		}
+		y += hudPanelSpacing
+	}
+
+	if g.hud.visible[HUDInstruction] {
+		opcode := s.Opcode()
+		lines := []string{
+			fmt.Sprintf("%03X: %04X %s", s.PC, opcode, Disassemble(opcode)),
+			fmt.Sprintf("DT %02X  ST %02X", s.DelayTimer, s.SoundTimer),
+		}
+		if g.c8.Fault != nil {
+			lines = append(lines, g.c8.Fault.Error())
+		}
+		for _, line := range lines {
+			if err := g.textLine(line, x, &y); err != nil {
+				return err
+			}
+		}
+		y += hudPanelSpacing
+	}
+
+	if g.hud.visible[HUDPerformance] {
+		ctl := g.controller
+		lines := []string{
+			fmt.Sprintf("FPS %d  Cycles/frame %d", g.hud.fps, g.hud.cyclesPerFrame),
+			fmt.Sprintf("Clock %.0fHz (%s)  %s", ctl.ClockHz(), g.c8.Timing, g.speedLabel()),
+		}
+		for _, line := range lines {
+			if err := g.textLine(line, x, &y); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+const hudPanelSpacing = 8
+
+// textLine: draws the text and moves y to the next line
+func (g *SDLGraphics) textLine(msg string, x int, y *int) error {
+	h, err := g.text(msg, x, *y)
+	if err != nil {
+		return err
+	}
+	*y += h
+	return nil
+}
+
+// text: draws the text using a cached texture, rendering it only the first time it's seen.
+// Returns the height of the drawn text.
+func (g *SDLGraphics) text(msg string, x, y int) (int, error) {
+	cached, ok := g.hud.textures[msg]
+	if !ok {
+		surface, err := g.font.RenderUTF8Blended(msg, sdl.Color{
+			R: 255,
+			G: 255,
+			B: 255,
+			A: 255,
+		})
+		if err != nil {
+			return 0, err
+		}
+		defer surface.Free()
+
+		texture, err := g.renderer.CreateTextureFromSurface(surface)
+		if err != nil {
+			return 0, err
+		}
+		cached = &cachedText{texture: texture, w: surface.W, h: surface.H}
+		g.hud.textures[msg] = cached
+	}
+	cached.used = true
+
+	err := g.renderer.Copy(cached.texture, nil, &sdl.Rect{
+		X: int32(x),
+		Y: int32(y),
+		W: cached.w,
+		H: cached.h,
+	})
+	return int(cached.h), err
+}
+
+// evictText: frees the textures that weren't drawn since the last call, so
+// values that keep changing (like registers) don't pile up textures
+func (g *SDLGraphics) evictText() {
+	for msg, cached := range g.hud.textures {
+		if !cached.used {
+			cached.texture.Destroy()
+			delete(g.hud.textures, msg)
+			continue
+		}
+		cached.used = false
+	}
+}
@@ -1,430 +1,345 @@
 package chip8
 
 import (
-	"fmt"
 	"math/rand"
 )
 
+// The instructions change the current state in place, they're run by execute with the
+// instruction already decoded and PC already pointing to the next opcode.
+
+// invalidInstruction: opcodes that aren't chip-8 instructions are ignored
+func (c *Chip8) invalidInstruction(in Instruction) {}
+
 // syscall: SYS instructions were originally called on chip-8 computers
 // but we don't need them on our emulation, so they're just gonna be ignored.
-func (c *Chip8) syscall(addr uint16) State {
-	fmt.Printf("Syscall w/ address: 0x%04x", addr)
-	return c.CurrState
-}
+func (c *Chip8) syscall(in Instruction) {}
 
 // clearScreen: CLS instruction sends a signal to clear the user interface
-func (c *Chip8) clearScreen() State {
-	nextState := c.CurrState
-	nextState.Graphics = [ScreenHeight]uint64{}
-	fmt.Println("Screen cleared!")
-	return nextState
+func (c *Chip8) clearScreen(in Instruction) {
+	c.CurrState.Graphics = [ScreenHeight]uint64{}
 }
 
 // returnFromSubroutine: RET instruction gets the address on  the top of
 // the stack and sets it as the current program counter, returning from the subroutine
-func (c *Chip8) returnFromSubroutine() State {
-	nextState := c.CurrState
-
-	addressToReturn := c.CurrState.Stack[c.CurrState.SP-0x1]
-	nextState.PC = addressToReturn
-	nextState.SP--
-
-	fmt.Printf("Return from subroutine to address: 0x%04x", addressToReturn)
-	return nextState
+func (c *Chip8) returnFromSubroutine(in Instruction) {
+	addressToReturn, ok := c.pop(&c.CurrState)
+	if !ok {
+		return
+	}
+	c.CurrState.PC = addressToReturn
 }
 
 // jumpToAddress: SYS instruction sets the current program counter to the
 // address received
-func (c *Chip8) jumpToAddress(addr uint16) State {
-	fmt.Printf("Jump to address: 0x%03x", addr)
-	nextState := c.CurrState
-	nextState.PC = addr
-	return nextState
+func (c *Chip8) jumpToAddress(in Instruction) {
+	c.CurrState.PC = in.NNN
 }
 
 // callSubroutine: CALL instruction adds current program counter to the stack and
 // sets it to the received address
-func (c *Chip8) callSubroutine(addr uint16) State {
-	fmt.Printf("Call subroutine on address: 0x%04x", addr)
-	nextState := c.CurrState
-
-	nextState.Stack[c.CurrState.SP] = c.CurrState.PC
-	nextState.SP++
-	nextState.PC = addr
-	return nextState
+func (c *Chip8) callSubroutine(in Instruction) {
+	if c.push(&c.CurrState, c.CurrState.PC) {
+		c.CurrState.PC = in.NNN
+	}
 }
 
 // skipIfVxEqualValue: SE Vx, byte instruction should skip the next opcode if Vx value
 // equals the value in kk
-func (c *Chip8) skipIfVxEqualValue(x, value uint8) State {
-	fmt.Printf("Skip next instruction if V%x value (0x%x) is equal to 0x%x", x, c.CurrState.V[x], value)
-	nextState := c.CurrState
-
-	if c.CurrState.V[x] == value {
-		nextState.PC += 2
-		fmt.Printf(" -> Skipped next instruction: OP %04x", nextState.Opcode())
-	} else {
-		fmt.Printf(" -> Continued without skip")
+func (c *Chip8) skipIfVxEqualValue(in Instruction) {
+	if c.CurrState.V[in.X] == in.KK {
+		c.CurrState.PC += 2
 	}
-
-	return nextState
 }
 
 // skipIfVxNotEqualValue: SNE Vx, byte instruction should skip the next opcode if Vx value
 // is NOT equals the value in kk
-func (c *Chip8) skipIfVxNotEqualValue(x, value uint8) State {
-	fmt.Printf("Skip next instruction if V%x value (0x%x) is NOT equal to 0x%x", x, c.CurrState.V[x], value)
-	nextState := c.CurrState
-
-	if c.CurrState.V[x] != value {
-		nextState.PC += 2
-		fmt.Printf(" -> Skipped next instruction: OP %04x", nextState.Opcode())
-	} else {
-		fmt.Printf(" -> Continued without skip")
+func (c *Chip8) skipIfVxNotEqualValue(in Instruction) {
+	if c.CurrState.V[in.X] != in.KK {
+		c.CurrState.PC += 2
 	}
-
-	return nextState
 }
 
 // skipIfVxEqualVy: SE Vx, Vy instruction should skip the next opcode if Vx value
 // equals the value in Vy
-func (c *Chip8) skipIfVxEqualVy(x, y uint8) State {
-	vx, vy := c.CurrState.V[x], c.CurrState.V[y]
-	fmt.Printf("Skip next instruction if V%x value (0x%x) is equal to V%x value (0x%x)", x, vx, y, vy)
-
-	nextState := c.CurrState
-
-	if vx == vy {
-		nextState.PC += 2
-		fmt.Printf(" -> Skipped next instruction: OP %04x", nextState.Opcode())
-	} else {
-		fmt.Printf(" -> Continued without skip")
+func (c *Chip8) skipIfVxEqualVy(in Instruction) {
+	if c.CurrState.V[in.X] == c.CurrState.V[in.Y] {
+		c.CurrState.PC += 2
 	}
-
-	return nextState
 }
 
 // loadIntoVx: LD Vx, byte Instruction 6xkk should load the received value into Vx
-func (c *Chip8) loadIntoVx(x, value uint8) State {
-	fmt.Printf("Loading value 0x%02x into V%x", value, x)
-	nextState := c.CurrState
-	nextState.V[x] = value
-	return nextState
+func (c *Chip8) loadIntoVx(in Instruction) {
+	c.CurrState.V[in.X] = in.KK
 }
 
 // addToVx: ADD Vx, byte Instruction 7xkk should add the received value into Vx
-func (c *Chip8) addToVx(x, value uint8) State {
-	fmt.Printf("Adding value 0x%02x to V%x", value, x)
-	nextState := c.CurrState
-	nextState.V[x] += value
-	return nextState
+func (c *Chip8) addToVx(in Instruction) {
+	c.CurrState.V[in.X] += in.KK
 }
 
 // loadIntoVx: LD Vx, Vy Instruction 8xy0 should load the Vy value into Vx
-func (c *Chip8) loadVxIntoVy(x, y uint8) State {
-	fmt.Printf("Loading value of V%x (0x%02x) into V%x", y, c.CurrState.V[y], x)
-	nextState := c.CurrState
-	nextState.V[x] = c.CurrState.V[y]
-	return nextState
+func (c *Chip8) loadVxIntoVy(in Instruction) {
+	c.CurrState.V[in.X] = c.CurrState.V[in.Y]
 }
 
 // loadBitwiseVxOrVyIntoVx: OR Vx, Vy Instruction 8xy1 should load the Vy BITWISE OR Vx value into Vx
-func (c *Chip8) loadBitwiseVxOrVyIntoVx(x, y uint8) State {
-	vx, vy := c.CurrState.V[x], c.CurrState.V[y]
-	fmt.Printf("Loading value of V%x (0x%02x) BITWISE OR V%x (0x%02x) into V%x", x, vx, y, vy, x)
-	nextState := c.CurrState
-
-	nextState.V[x] = vx | vy
-	return nextState
+func (c *Chip8) loadBitwiseVxOrVyIntoVx(in Instruction) {
+	s := &c.CurrState
+	s.V[in.X] = s.V[in.X] | s.V[in.Y]
+	if c.Quirks.Logic {
+		s.V[0xF] = 0x00
+	}
 }
 
 // loadBitwiseVxAndVyIntoVx: AND Vx, Vy Instruction 8xy2 should load the Vy BITWISE AND Vx value into Vx
-func (c *Chip8) loadBitwiseVxAndVyIntoVx(x, y uint8) State {
-	vx, vy := c.CurrState.V[x], c.CurrState.V[y]
-	fmt.Printf("Loading value of V%x (0x%02x) BITWISE AND V%x (0x%02x) into V%x", x, vx, y, vy, x)
-	nextState := c.CurrState
-
-	nextState.V[x] = vx & vy
-	return nextState
+func (c *Chip8) loadBitwiseVxAndVyIntoVx(in Instruction) {
+	s := &c.CurrState
+	s.V[in.X] = s.V[in.X] & s.V[in.Y]
+	if c.Quirks.Logic {
+		s.V[0xF] = 0x00
+	}
 }
 
 // loadBitwiseVxExclusiveOrVyIntoVx: XOR Vx, Vy Instruction 8xy3 should load the Vy BITWISE XOR Vx value into Vx
-func (c *Chip8) loadBitwiseVxExclusiveOrVyIntoVx(x, y uint8) State {
-	vx, vy := c.CurrState.V[x], c.CurrState.V[y]
-	fmt.Printf("Loading value of V%x (0x%02x) BITWISE XOR V%x (0x%02x) into V%x", x, vx, y, vy, x)
-	nextState := c.CurrState
-
-	nextState.V[x] = vx ^ vy
-	return nextState
+func (c *Chip8) loadBitwiseVxExclusiveOrVyIntoVx(in Instruction) {
+	s := &c.CurrState
+	s.V[in.X] = s.V[in.X] ^ s.V[in.Y]
+	if c.Quirks.Logic {
+		s.V[0xF] = 0x00
+	}
 }
 
 // addVyToVx: Instruction 8xy4 should add the Vy value into the current Vx value
 // If the sum overflows (so, it's bigger than 0xFF), set VF to 1
-func (c *Chip8) addVyToVx(x, y uint8) State {
-	vx, vy := c.CurrState.V[x], c.CurrState.V[y]
-	fmt.Printf("Loading value of V%x (0x%02x) + V%x (0x%02x) into V%x", x, vx, y, vy, x)
-	nextState := c.CurrState
-
-	var sum uint16 = uint16(vx) + uint16(vy)
-	nextState.V[x] = uint8(sum & 0x00FF)
+func (c *Chip8) addVyToVx(in Instruction) {
+	s := &c.CurrState
+	var sum uint16 = uint16(s.V[in.X]) + uint16(s.V[in.Y])
+	s.V[in.X] = uint8(sum & 0x00FF)
 
 	if sum > 0xFF {
-		nextState.V[0xF] = 0x01
-		fmt.Printf("\tCarry flag set to 1")
+		s.V[0xF] = 0x01
 	} else {
-		nextState.V[0xF] = 0x00
-		fmt.Printf("\tCarry flag set to 0")
+		s.V[0xF] = 0x00
 	}
-
-	return nextState
 }
 
 // subtractVxByVy: Instruction 8xy5 should subtract the Vy value into the current Vx value
-// If the sub overflows (so, it's less than 0), set VF to 1
-func (c *Chip8) subtractVxByVy(x, y uint8) State {
-	vx, vy := c.CurrState.V[x], c.CurrState.V[y]
-	fmt.Printf("Loading value of V%x (0x%02x) - V%x (0x%02x) into V%x", x, vx, y, vy, x)
-	nextState := c.CurrState
-
-	if vx > vy {
-		nextState.V[0xF] = 0x01
-		fmt.Printf("\tNo Borrow flag set to 1")
-	} else {
-		nextState.V[0xF] = 0x00
-		fmt.Printf("\tNo Borrow flag set to 0")
-	}
-
-	nextState.V[x] = vx - vy
-
-	return nextState
+// VF is set to 1 when there was NO borrow (Vx >= Vy), after Vx so 8Fy5 is left with the flag
+func (c *Chip8) subtractVxByVy(in Instruction) {
+	s := &c.CurrState
+	vx, vy := s.V[in.X], s.V[in.Y]
+	s.V[in.X] = vx - vy
+	s.V[0xF] = boolToFlag(vx >= vy)
 }
 
-// shiftVxRight: SHR Vx {, Vy} Instruction 8xy6 should shift right the bits on Vx and VF should be set to 1 if least significant bit is 1
-func (c *Chip8) shiftVxRight(x uint8) State {
-	fmt.Printf("Shifting right the value of V%x (0x%02x)", x, c.CurrState.V[x])
-	nextState := c.CurrState
-
-	nextState.V[x] = c.CurrState.V[x] >> 1
-	nextState.V[0xF] = nextState.V[x] & 0x01
-
-	return nextState
+// shiftVxRight: SHR Vx {, Vy} Instruction 8xy6 should shift right the bits on Vx (or Vy without the shift quirk)
+// and VF should be set to the bit shifted out, the least significant one
+func (c *Chip8) shiftVxRight(in Instruction) {
+	s := &c.CurrState
+	value := s.V[c.shiftSource(in.X, in.Y)]
+	s.V[in.X] = value >> 1
+	s.V[0xF] = value & 0x01
 }
 
 // loadVySubtractedByVxIntoVx: SUB Vx, Vy Instruction 8xy7 should load the Vy subtracted by Vx value into Vx
-func (c *Chip8) loadVySubtractedByVxIntoVx(x, y uint8) State {
-	vx, vy := c.CurrState.V[x], c.CurrState.V[y]
-	fmt.Printf("Loading value of V%x (0x%02x) - V%x (0x%02x) into V%x", y, vy, x, vx, x)
-	nextState := c.CurrState
-
-	if vy > vx {
-		nextState.V[0xF] = 0x01
-		fmt.Printf("\tNo Borrow flag set to 1")
-	} else {
-		nextState.V[0xF] = 0x00
-		fmt.Printf("\tNo Borrow flag set to 0")
+// VF is set to 1 when there was NO borrow (Vy >= Vx), after Vx so 8Fy7 is left with the flag
+func (c *Chip8) loadVySubtractedByVxIntoVx(in Instruction) {
+	s := &c.CurrState
+	vx, vy := s.V[in.X], s.V[in.Y]
+	s.V[in.X] = vy - vx
+	s.V[0xF] = boolToFlag(vy >= vx)
+}
+
+// shiftVxLeft: SHL Vx {, Vy} Instruction 8xyE should shift left the bits on Vx (or Vy without the shift quirk)
+// and VF should be set to the bit shifted out, the most significant one
+func (c *Chip8) shiftVxLeft(in Instruction) {
+	s := &c.CurrState
+	value := s.V[c.shiftSource(in.X, in.Y)]
+	s.V[in.X] = value << 1
+	s.V[0xF] = value >> (ByteSize - 1)
+}
+
+// shiftSource: the register shifted by 8xy6 and 8xyE, Vx with the shift quirk and Vy without it
+func (c *Chip8) shiftSource(x, y uint8) uint8 {
+	if c.Quirks.Shift {
+		return x
 	}
-
-	nextState.V[x] = vy - vx
-
-	return nextState
-}
-
-// shiftVxRight: SHL Vx {, Vy} Instruction 8xyE should shift left the bits on Vx and VF should be set to 1 if most significant bit is 1
-func (c *Chip8) shiftVxLeft(x uint8) State {
-	fmt.Printf("Shifting left the value of V%x (0x%02x)", x, c.CurrState.V[x])
-	nextState := c.CurrState
-
-	nextState.V[x] = c.CurrState.V[x] << 1
-	nextState.V[0xF] = nextState.V[x] >> (ByteSize - 1)
-
-	return nextState
+	return y
 }
 
 // skipIfVxNotEqualVy: SNE Vx, Vy instruction should skip the next opcode if Vx value
 // is NOT equals the value in Vy
-func (c *Chip8) skipIfVxNotEqualVy(x, y uint8) State {
-	vx, vy := c.CurrState.V[x], c.CurrState.V[y]
-	fmt.Printf("Skip next instruction if V%x value (0x%x) is NOT equal to V%x value (0x%x)", x, vx, y, vy)
-
-	nextState := c.CurrState
-
-	if vx != vy {
-		nextState.PC += 2
-		fmt.Printf(" -> Skipped next instruction: OP %04x", nextState.Opcode())
-	} else {
-		fmt.Printf(" -> Continued without skip")
+func (c *Chip8) skipIfVxNotEqualVy(in Instruction) {
+	if c.CurrState.V[in.X] != c.CurrState.V[in.Y] {
+		c.CurrState.PC += 2
 	}
-
-	return nextState
 }
 
 // loadAddressIntoI: LD I, addr instruction Annn should load the received address into I
-func (c *Chip8) loadAddressIntoI(addr uint16) State {
-	fmt.Printf("Loading value 0x%03x into I", addr)
-	nextState := c.CurrState
-	nextState.I = addr
-	return nextState
+func (c *Chip8) loadAddressIntoI(in Instruction) {
+	c.CurrState.I = in.NNN
 }
 
 // jumpToAddressPlusV0: JMP V0, addr instruction Bnnn should jump the program counter to the received address + V0
-func (c *Chip8) jumpToAddressPlusV0(addr uint16) State {
-	sum := uint16(c.CurrState.V[0x0]) + addr
-	fmt.Printf("Jump to address of V0 + %03x: 0x%03x", addr, sum)
-	nextState := c.CurrState
-	nextState.PC = sum
-	return nextState
+// (or + Vx with the jump quirk, x being the highest nibble of the address)
+func (c *Chip8) jumpToAddressPlusV0(in Instruction) {
+	reg := uint8(0x0)
+	if c.Quirks.Jump {
+		reg = in.X
+	}
+	c.CurrState.PC = uint16(c.CurrState.V[reg]) + in.NNN
 }
 
 // loadRandomValueBitwiseAndValueIntoVx: RND Vx, byte instruction Cxkk should load a random value into Vx BITWISE AND received value
-func (c *Chip8) loadRandomValueBitwiseAndValueIntoVx(x, value uint8) State {
-	nextState := c.CurrState
-	randomValue := uint8(rand.Intn(0x100)) & value
-	fmt.Printf("Loading value 0x%02x into V%x", randomValue, x)
-	nextState.V[x] = randomValue
-	return nextState
-}
-
-// drawSprite: (DRW Vx, Vy, nibble) Instruction Dxyn draws a sprite
-func (c *Chip8) drawSprite(x, y, value uint8) State {
-	vx := c.CurrState.V[x]
-	vy := c.CurrState.V[y]
-	fmt.Printf("Drawing a sprite (0x%03x) on coords: %d, %d", c.CurrState.I, vx, vy)
-	nextState := c.CurrState
-	var width uint8 = 8
-	var height uint8 = value
-
-	nextState.V[0xF] = 0x00
-	for row := uint8(0); row < height; row++ {
-		spriteRow := c.CurrState.I + uint16(row)
-		sprite := c.CurrState.Memory[spriteRow]
-
-		for col := uint8(0); col < width; col++ {
-			if sprite&(FirstFontBitMask>>col) != 0 {
-				if isAlreadyPainted := nextState.SetPixel(vx+col, vy+row); isAlreadyPainted {
-					nextState.V[0xF] = 0x01
+func (c *Chip8) loadRandomValueBitwiseAndValueIntoVx(in Instruction) {
+	c.CurrState.V[in.X] = uint8(rand.Intn(0x100)) & in.KK
+}
+
+// drawSprite: (DRW Vx, Vy, nibble) Instruction Dxyn draws a sprite. The starting coordinate
+// wraps around the screen, the rest of the sprite wraps or is clipped depending on the Wrap quirk.
+func (c *Chip8) drawSprite(in Instruction) {
+	s := &c.CurrState
+	vx := int(s.V[in.X]) % ScreenWidth
+	vy := int(s.V[in.Y]) % ScreenHeight
+	const width = ByteSize
+	height := int(in.N)
+
+	if c.Hooks.SpriteDrawn != nil {
+		c.Hooks.SpriteDrawn(s.I, in.N)
+	}
+
+	collidedRows, clippedRows := 0, 0
+	for row := 0; row < height; row++ {
+		py := vy + row
+		if py >= ScreenHeight {
+			if !c.Quirks.Wrap {
+				clippedRows = height - row
+				break
+			}
+			py %= ScreenHeight
+		}
+		spriteRow := s.I + uint16(row)
+		sprite := c.read(s, spriteRow, AccessSprite)
+
+		collided := false
+		for col := 0; col < width; col++ {
+			if sprite&(FirstFontBitMask>>col) == 0 {
+				continue
+			}
+			px := vx + col
+			if px >= ScreenWidth {
+				if !c.Quirks.Wrap {
+					break
 				}
+				px %= ScreenWidth
 			}
+			if isAlreadyPainted := s.SetPixel(uint8(px), uint8(py)); isAlreadyPainted {
+				collided = true
+			}
+		}
+		if collided {
+			collidedRows++
 		}
 	}
 
-	return nextState
+	s.V[0xF] = 0x00
+	switch {
+	case c.Quirks.CountClippedRows && !c.Quirks.Wrap:
+		s.V[0xF] = uint8(collidedRows + clippedRows)
+	case collidedRows > 0:
+		s.V[0xF] = 0x01
+	}
 }
 
-// skipIfVxKeyIsPressed: (SKP Vx Key) Instruction Ex9E should skip next instruction if Vx key is pressed
-func (c *Chip8) skipIfVxKeyIsPressed(x uint8) State {
-	nextState := c.CurrState
-	vx := c.CurrState.V[x]
-	fmt.Printf("Skip next instruction if V%x (0x%02x) key is pressed", x, vx)
-
-	if c.CurrState.Keyboard[vx] {
-		nextState.PC += 2
-		fmt.Printf(" -> Skipped next instruction: OP %04x", nextState.Opcode())
-	} else {
-		fmt.Printf(" -> Continued without skip")
+// skipIfVxKeyIsPressed: (SKP Vx Key) Instruction Ex9E should skip next instruction if Vx key is pressed.
+// Only the lowest nibble of Vx selects the key.
+func (c *Chip8) skipIfVxKeyIsPressed(in Instruction) {
+	if c.CurrState.Keyboard[c.CurrState.V[in.X]&0x0F] {
+		c.CurrState.PC += 2
 	}
-	return nextState
 }
 
 // skipIfVxKeyIsNotPressed: (SKNP Vx Key) Instruction ExA1 should skip next instruction if Vx key is NOT pressed
-func (c *Chip8) skipIfVxKeyIsNotPressed(x uint8) State {
-	nextState := c.CurrState
-	vx := c.CurrState.V[x]
-	fmt.Printf("Skip next instruction if V%x (0x%02x) key is released", x, vx)
-
-	if !c.CurrState.Keyboard[vx] {
-		nextState.PC += 2
-		fmt.Printf(" -> Skipped next instruction: OP %04x", nextState.Opcode())
-	} else {
-		fmt.Printf(" -> Continued without skip")
+func (c *Chip8) skipIfVxKeyIsNotPressed(in Instruction) {
+	if !c.CurrState.Keyboard[c.CurrState.V[in.X]&0x0F] {
+		c.CurrState.PC += 2
 	}
-	return nextState
 }
 
 // loadDelayTimerIntoVx: LD Vx, DT Instruction Fx15 should load the Vx value into Delay Timer
-func (c *Chip8) loadDelayTimerIntoVx(x uint8) State {
-	fmt.Printf("Loading value 0x%02x into V%x", c.CurrState.DelayTimer, x)
-	nextState := c.CurrState
-	nextState.V[x] = c.CurrState.DelayTimer
-	return nextState
+func (c *Chip8) loadDelayTimerIntoVx(in Instruction) {
+	c.CurrState.V[in.X] = c.CurrState.DelayTimer
 }
 
 // waitButtonPressAndLoadIntoVx: (LD Vx, Key) Instruction Fx0A should wait until a key is pressed and then load the key into Vx
-func (c *Chip8) waitButtonPressAndLoadIntoVx(x uint8) State {
-	nextState := c.CurrState
-	fmt.Printf("Waiting for key press...")
+func (c *Chip8) waitButtonPressAndLoadIntoVx(in Instruction) {
 	for i, key := range c.CurrState.Keyboard {
 		if key {
-			nextState.V[x] = uint8(i)
-			return nextState
+			c.CurrState.V[in.X] = uint8(i)
+			return
 		}
 	}
-	nextState.PC -= 2
-	return nextState
+	c.CurrState.PC -= 2
 }
 
 // loadVxIntoDelayTimer: LD DT, Vx Instruction Fx15 should load the Vx value into Delay Timer
-func (c *Chip8) loadVxIntoDelayTimer(x uint8) State {
-	fmt.Printf("Loading value V%x value (0x%02x) into Delay Timer", x, c.CurrState.V[x])
-	nextState := c.CurrState
-	nextState.DelayTimer = nextState.V[x]
-	return nextState
+func (c *Chip8) loadVxIntoDelayTimer(in Instruction) {
+	c.CurrState.DelayTimer = c.CurrState.V[in.X]
 }
 
 // loadVxIntoSoundTimer: LD Vx, ST Instruction Fx18 should load the Vx value into Sound Timer
-func (c *Chip8) loadVxIntoSoundTimer(x uint8) State {
-	fmt.Printf("Loading value V%x value (0x%02x) into Sound Timer", x, c.CurrState.V[x])
-	nextState := c.CurrState
-	nextState.SoundTimer = nextState.V[x]
-	return nextState
+func (c *Chip8) loadVxIntoSoundTimer(in Instruction) {
+	c.CurrState.SoundTimer = c.CurrState.V[in.X]
 }
 
 // addVxToI: ADD I, Vx Instruction Fx1E adds the value of Vx into the existing value in I
-func (c *Chip8) addVxToI(x uint8) State {
-	nextState := c.CurrState
-	fmt.Printf("Loading value of I (0x%03x) + V%x (0x%02x) into I", c.CurrState.I, x, c.CurrState.V[x])
-	nextState.I += uint16(c.CurrState.V[x])
-	return nextState
+func (c *Chip8) addVxToI(in Instruction) {
+	c.CurrState.I += uint16(c.CurrState.V[in.X])
 }
 
 // loadVxDigitSpriteAddressIntoI: LD F, Vx Instruction Fx29 loads the address of the Vx character sprite into I
-func (c *Chip8) loadVxDigitSpriteAddressIntoI(x uint8) State {
-	nextState := c.CurrState
-	fmt.Printf("Loading address of the V%x character sprite (%x) into I", x, c.CurrState.V[x])
-	nibble := uint16(0x0F & c.CurrState.V[x])
-	nextState.I = FontsStartAddress + (5 * nibble)
-	return nextState
+func (c *Chip8) loadVxDigitSpriteAddressIntoI(in Instruction) {
+	nibble := uint16(0x0F & c.CurrState.V[in.X])
+	c.CurrState.I = c.FontAddress + (fontGlyphHeight * nibble)
 }
 
 // loadVxDigitsIntoI: (LD B, Vx) Instruction Fx33 should load the Vx digits into Memory at I, I+1 and I+3
-func (c *Chip8) loadVxDigitsIntoI(x uint8) State {
-	nextState := c.CurrState
-	vx := c.CurrState.V[x]
-	fmt.Printf("Loading digits of the V%x (%x) into I (0x%03x), I+1 and I+2", x, vx, c.CurrState.I)
+func (c *Chip8) loadVxDigitsIntoI(in Instruction) {
+	s := &c.CurrState
+	vx := s.V[in.X]
 	firstDigit := vx / 100
 	secondDigit := vx / 10 % 10
 	thirdDigit := vx % 10
-	nextState.Memory[c.CurrState.I] = firstDigit
-	nextState.Memory[c.CurrState.I+1] = secondDigit
-	nextState.Memory[c.CurrState.I+2] = thirdDigit
-	return nextState
+	c.write(s, s.I, firstDigit)
+	c.write(s, s.I+1, secondDigit)
+	c.write(s, s.I+2, thirdDigit)
 }
 
 // loadRangeV0ToVxIntoMemoryStartingFromI: (LD [I], Vx) Instruction Fx55 should loads the V[0:x] into memory starting by I
-func (c *Chip8) loadRangeV0ToVxIntoMemoryStartingFromI(x uint8) State {
-	nextState := c.CurrState
-	fmt.Printf("Loading values from V0 to V%x starting from I (0x%03x)", x, c.CurrState.I)
-	for i := uint8(0); i <= x; i++ {
-		nextState.Memory[c.CurrState.I+uint16(i)] = c.CurrState.V[i]
+func (c *Chip8) loadRangeV0ToVxIntoMemoryStartingFromI(in Instruction) {
+	s := &c.CurrState
+	for i := uint8(0); i <= in.X; i++ {
+		c.write(s, s.I+uint16(i), s.V[i])
 	}
-	return nextState
+	s.I = c.incrementedI(in.X)
 }
 
 // loadMemoryStartingFromIIntoRangeV0ToVx: (LD Vx, [I]) Instruction Fx65 should loads the into V[0:x] the memory values starting by I
-func (c *Chip8) loadMemoryStartingFromIIntoRangeV0ToVx(x uint8) State {
-	nextState := c.CurrState
-	fmt.Printf("Loading values into V0 to V%x starting from I (0x%03x)", x, c.CurrState.I)
-	for i := uint8(0); i <= x; i++ {
-		nextState.V[i] = c.CurrState.Memory[c.CurrState.I+uint16(i)]
+func (c *Chip8) loadMemoryStartingFromIIntoRangeV0ToVx(in Instruction) {
+	s := &c.CurrState
+	for i := uint8(0); i <= in.X; i++ {
+		s.V[i] = c.read(s, s.I+uint16(i), AccessData)
+	}
+	s.I = c.incrementedI(in.X)
+}
+
+// incrementedI: the value of I after Fx55 and Fx65, which depends on the memory quirks
+func (c *Chip8) incrementedI(x uint8) uint16 {
+	switch {
+	case c.Quirks.MemoryLeaveIUnchanged:
+		return c.CurrState.I
+	case c.Quirks.MemoryIncrementByX:
+		return c.CurrState.I + uint16(x)
 	}
-	return nextState
+	return c.CurrState.I + uint16(x) + 1
 }
@@ -0,0 +1,247 @@
+package chip8
+
+import "fmt"
+
+// Backend decides how Run gets the instructions executed
+type Backend int
+
+const (
+	// BackendInterpreter: every instruction is fetched, decoded and run by Tick
+	BackendInterpreter Backend = iota
+	// BackendJIT: the basic blocks are translated to chains of Go closures the first time they
+	// run, with the operands already bound, and taken from a cache by address after that
+	BackendJIT
+)
+
+// Backends: the backends by name
+var Backends = map[string]Backend{
+	"interpreter": BackendInterpreter,
+	"jit":         BackendJIT,
+}
+
+func (b Backend) String() string {
+	for name, backend := range Backends {
+		if backend == b {
+			return name
+		}
+	}
+	return fmt.Sprintf("Backend(%d)", int(b))
+}
+
+// jitMaxBlockSize: how many instructions a block has at most, so long runs of straight code
+// don't keep Run from checking how many ticks are left
+const jitMaxBlockSize = 64
+
+// jitOp: an instruction translated to a closure
+type jitOp struct {
+	run    func(c *Chip8)
+	opcode uint16
+}
+
+type jitBlock struct {
+	start uint16
+	ops   []jitOp
+}
+
+// jitCache: the blocks by the address they start at. Writes to the bytes of a translated
+// instruction flush every block, self-modifying code is rare enough to not track more than that.
+type jitCache struct {
+	blocks [MemorySize]*jitBlock
+	code   [MemorySize]bool
+	// flushes counts the flushes, so a running block notices it changed itself
+	flushes int
+}
+
+// Run: runs the ticks, the same as calling Tick that many times. With the JIT backend the
+// instructions run from translated blocks, unless something has to see every fetch: the
+// MemoryRead and InstructionFetched hooks, Log, the history and COSMAC VIP timing, whose
+// cycle budget is checked after every instruction. Those run on the interpreter, and so do
+// the opcodes that run past the end of memory.
+func (c *Chip8) Run(ticks int) {
+	for ticks > 0 && c.Fault == nil {
+		if c.Backend == BackendJIT && c.canRunJIT() {
+			if b := c.jitBlock(c.CurrState.PC); b != nil {
+				ticks -= c.runBlock(b, ticks)
+				continue
+			}
+		}
+		c.Tick(0)
+		ticks--
+	}
+}
+
+func (c *Chip8) canRunJIT() bool {
+	return c.Hooks.MemoryRead == nil && c.Hooks.InstructionFetched == nil && c.Log == nil &&
+		c.HistoryLimit == 0 && c.Timing == TimingFixed
+}
+
+// runBlock: runs the block from its start doing for every instruction what Tick does, until
+// the ticks are spent, a Dxyn waits for the display, the machine faults or the block is flushed.
+// Returns the ticks used.
+func (c *Chip8) runBlock(b *jitBlock, ticks int) int {
+	flushes := c.jit.flushes
+	ran := 0
+	for i := range b.ops {
+		op := &b.ops[i]
+		pc := b.start + uint16(i)*OpcodeSize
+		c.instructionPC = pc
+		if c.waitForVBlank(op.opcode) {
+			c.countDownFixedTimers(&c.CurrState)
+			c.TickCount++
+			return ran + 1
+		}
+		c.CurrState.PC = pc + OpcodeSize
+		op.run(c)
+		if int(c.CurrState.PC) >= MemorySize {
+			c.CurrState.PC, _ = c.resolve(c.CurrState.PC)
+		}
+		c.Executed++
+		c.countDownFixedTimers(&c.CurrState)
+		c.TickCount++
+		ran++
+		if ran == ticks || c.Fault != nil || c.jit.flushes != flushes {
+			break
+		}
+	}
+	return ran
+}
+
+// jitBlock: the translated block that starts at pc, nil when the opcode there runs past
+// the end of memory
+func (c *Chip8) jitBlock(pc uint16) *jitBlock {
+	if int(pc) >= MemorySize-1 {
+		return nil
+	}
+	if c.jit == nil {
+		c.jit = &jitCache{}
+	}
+	if b := c.jit.blocks[pc]; b != nil {
+		return b
+	}
+
+	b := &jitBlock{start: pc}
+	for addr := pc; int(addr) < MemorySize-1 && len(b.ops) < jitMaxBlockSize; addr += OpcodeSize {
+		in := Decode(uint16(c.CurrState.Memory[addr])<<ByteSize | uint16(c.CurrState.Memory[addr+1]))
+		b.ops = append(b.ops, jitOp{run: translate(in), opcode: in.Opcode})
+		c.jit.code[addr], c.jit.code[addr+1] = true, true
+		if changesFlow(in.Op) {
+			break
+		}
+	}
+	c.jit.blocks[pc] = b
+	return b
+}
+
+// changesFlow: true for the instructions that set PC, which end blocks
+func changesFlow(op Operation) bool {
+	switch op {
+	case OpJP, OpCALL, OpRET, OpJPV0, OpSEByte, OpSNEByte, OpSERegister, OpSNERegister, OpSKP, OpSKNP, OpLDVxK:
+		return true
+	}
+	return false
+}
+
+// flushJIT: drops every translated block
+func (c *Chip8) flushJIT() {
+	if c.jit == nil {
+		return
+	}
+	c.jit.blocks = [MemorySize]*jitBlock{}
+	c.jit.code = [MemorySize]bool{}
+	c.jit.flushes++
+}
+
+// translate: the closure that runs the instruction. The instructions that don't depend on the
+// quirks and don't touch memory get their own closure with the operands bound; the rest run
+// their handler from the interpreter with the decoded instruction.
+func translate(in Instruction) func(c *Chip8) {
+	x, y, kk, nnn := in.X, in.Y, in.KK, in.NNN
+	switch in.Op {
+	case OpJP:
+		return func(c *Chip8) { c.CurrState.PC = nnn }
+	case OpSEByte:
+		return func(c *Chip8) {
+			if c.CurrState.V[x] == kk {
+				c.CurrState.PC += OpcodeSize
+			}
+		}
+	case OpSNEByte:
+		return func(c *Chip8) {
+			if c.CurrState.V[x] != kk {
+				c.CurrState.PC += OpcodeSize
+			}
+		}
+	case OpSERegister:
+		return func(c *Chip8) {
+			if c.CurrState.V[x] == c.CurrState.V[y] {
+				c.CurrState.PC += OpcodeSize
+			}
+		}
+	case OpSNERegister:
+		return func(c *Chip8) {
+			if c.CurrState.V[x] != c.CurrState.V[y] {
+				c.CurrState.PC += OpcodeSize
+			}
+		}
+	case OpLDByte:
+		return func(c *Chip8) { c.CurrState.V[x] = kk }
+	case OpADDByte:
+		return func(c *Chip8) { c.CurrState.V[x] += kk }
+	case OpLDRegister:
+		return func(c *Chip8) { c.CurrState.V[x] = c.CurrState.V[y] }
+	case OpADDRegister:
+		return func(c *Chip8) {
+			s := &c.CurrState
+			sum := uint16(s.V[x]) + uint16(s.V[y])
+			s.V[x] = uint8(sum)
+			s.V[0xF] = uint8(sum >> ByteSize)
+		}
+	case OpSUB:
+		return func(c *Chip8) {
+			s := &c.CurrState
+			vx, vy := s.V[x], s.V[y]
+			s.V[x] = vx - vy
+			s.V[0xF] = boolToFlag(vx >= vy)
+		}
+	case OpSUBN:
+		return func(c *Chip8) {
+			s := &c.CurrState
+			vx, vy := s.V[x], s.V[y]
+			s.V[x] = vy - vx
+			s.V[0xF] = boolToFlag(vy >= vx)
+		}
+	case OpLDI:
+		return func(c *Chip8) { c.CurrState.I = nnn }
+	case OpADDI:
+		return func(c *Chip8) { c.CurrState.I += uint16(c.CurrState.V[x]) }
+	case OpSKP:
+		return func(c *Chip8) {
+			if c.CurrState.Keyboard[c.CurrState.V[x]&0x0F] {
+				c.CurrState.PC += OpcodeSize
+			}
+		}
+	case OpSKNP:
+		return func(c *Chip8) {
+			if !c.CurrState.Keyboard[c.CurrState.V[x]&0x0F] {
+				c.CurrState.PC += OpcodeSize
+			}
+		}
+	case OpLDVxDT:
+		return func(c *Chip8) { c.CurrState.V[x] = c.CurrState.DelayTimer }
+	case OpLDDTVx:
+		return func(c *Chip8) { c.CurrState.DelayTimer = c.CurrState.V[x] }
+	case OpLDSTVx:
+		return func(c *Chip8) { c.CurrState.SoundTimer = c.CurrState.V[x] }
+	case OpSYS, OpInvalid:
+		return func(c *Chip8) {}
+	}
+	handler := handlers[in.Op]
+	return func(c *Chip8) { handler(c, in) }
+}
+
+func boolToFlag(b bool) uint8 {
+	if b {
+		return 0x01
+	}
+	return 0x00
+}
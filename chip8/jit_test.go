@@ -0,0 +1,168 @@
+package chip8
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// backendRun: what a machine went through running a program, to compare backends
+type backendRun struct {
+	frames   []TraceEntry
+	final    State
+	ticks    int64
+	executed int64
+	fault    error
+}
+
+// runBackend: runs the program with the backend for the frames, pressing the keys of the input
+// script, and records the registers after every frame
+func runBackend(c *Chip8, backend Backend, frames int, input []keyInput) backendRun {
+	rand.Seed(1)
+	c.Backend = backend
+	ctl := NewController(c)
+	var run backendRun
+	for frame := 0; frame < frames; frame++ {
+		for _, in := range input {
+			switch {
+			case in.frame != frame:
+			case in.press:
+				c.PressKey(in.key)
+			default:
+				c.ReleaseKey(in.key)
+			}
+		}
+		ctl.StepFrame()
+		run.frames = append(run.frames, NewTraceEntry(c.TickCount, &c.CurrState))
+	}
+	run.final, run.ticks, run.executed, run.fault = c.CurrState, c.TickCount, c.Executed, c.Fault
+	return run
+}
+
+// assertSameRun: the frames where the JIT run diverged from the interpreter one
+func assertSameRun(t *testing.T, want, got backendRun, name string) {
+	for frame := range want.frames {
+		if diff := want.frames[frame].Diff(got.frames[frame]); len(diff) > 0 {
+			t.Errorf("%s diverges on frame %d on %v:\n- %v\n+ %v", name, frame, diff, want.frames[frame], got.frames[frame])
+			return
+		}
+	}
+	assert.Equal(t, want.final, got.final, name)
+	assert.Equal(t, want.ticks, got.ticks, name)
+	assert.Equal(t, want.executed, got.executed, name)
+	assert.Equal(t, want.fault, got.fault, name)
+}
+
+func TestJIT(t *testing.T) {
+	t.Run("Run with the JIT should match the interpreter on the bundled ROMs", func(t *testing.T) {
+		for _, tc := range conformanceROMs {
+			rom, err := LoadROMFile(filepath.Join("..", "roms", tc.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			runs := map[Backend]backendRun{}
+			for _, backend := range []Backend{BackendInterpreter, BackendJIT} {
+				c := New()
+				if err := c.LoadROM(rom); err != nil {
+					t.Fatal(err)
+				}
+				runs[backend] = runBackend(c, backend, tc.frames, tc.input)
+			}
+			assertSameRun(t, runs[BackendInterpreter], runs[BackendJIT], tc.name)
+		}
+	})
+
+	t.Run("Run with the JIT should match the interpreter on random programs and quirks", func(t *testing.T) {
+		quirks := []Quirks{DefaultQuirks, {}, {Logic: true, Jump: true, MemoryIncrementByX: true}, {Wrap: true, DisplayWait: true}}
+		for seed := int64(1); seed <= 200; seed++ {
+			program := make([]uint8, 0x100)
+			r := rand.New(rand.NewSource(seed))
+			r.Read(program)
+			input := hold(uint8(seed%0x10), 5, 15)
+
+			runs := map[Backend]backendRun{}
+			for _, backend := range []Backend{BackendInterpreter, BackendJIT} {
+				c := New()
+				c.Quirks = quirks[seed%int64(len(quirks))]
+				c.AddressMode = AddressMode(seed / 2 % 2)
+				c.TickRate = 1 + int(seed%20)
+				if err := c.LoadGame(program); err != nil {
+					t.Fatal(err)
+				}
+				runs[backend] = runBackend(c, backend, 30, input)
+			}
+			assertSameRun(t, runs[BackendInterpreter], runs[BackendJIT], "random program")
+		}
+	})
+
+	t.Run("Run should translate the block again after the program writes over it", func(t *testing.T) {
+		c := New()
+		c.Backend = BackendJIT
+		c.LoadGame([]uint8{
+			0x60, 0x65, // 200: LD V0, 0x65
+			0x61, 0x02, // 202: LD V1, 0x02
+			0xA2, 0x08, // 204: LD I, 0x208
+			0xF1, 0x55, // 206: LD [I], V1, writes LD V5, 2 over the next instruction
+			0x65, 0x01, // 208: LD V5, 1
+			0x12, 0x0A, // 20A: JP 0x20A
+		})
+		c.Run(6)
+		assert.Equal(t, uint8(2), c.CurrState.V[5])
+		assert.Equal(t, uint16(0x20A), c.CurrState.PC)
+		assert.Equal(t, int64(6), c.TickCount)
+	})
+
+	t.Run("Run should fall back to the interpreter when the fetches are watched", func(t *testing.T) {
+		c := New()
+		c.Backend = BackendJIT
+		c.LoadGame(benchmarkROM)
+		fetched := 0
+		c.Hooks.InstructionFetched = func(pc uint16, in Instruction) {
+			fetched++
+		}
+		c.Run(100)
+		assert.Equal(t, 100, fetched)
+		assert.Equal(t, int64(100), c.TickCount)
+	})
+
+	t.Run("Run should stop on faults", func(t *testing.T) {
+		c := New()
+		c.Backend = BackendJIT
+		c.LoadGame([]uint8{0x60, 0x01, 0x00, 0xEE})
+		c.Run(10)
+		assert.Error(t, c.Fault)
+		assert.Equal(t, uint16(0x202), c.Fault.(*Fault).PC)
+		assert.Equal(t, int64(2), c.TickCount, "Should stop after the tick that faulted, like Tick")
+	})
+
+	t.Run("Backends should have names", func(t *testing.T) {
+		assert.Equal(t, "jit", BackendJIT.String())
+		assert.Equal(t, BackendInterpreter, Backends["interpreter"])
+	})
+}
+
+// FuzzJIT: runs random programs on both backends and checks they end up in the same place
+func FuzzJIT(f *testing.F) {
+	addROMSeeds(f, func(rom []uint8) {
+		f.Add(rom, uint8(0))
+	})
+	f.Fuzz(func(t *testing.T, rom []uint8, options uint8) {
+		runs := map[Backend]backendRun{}
+		for _, backend := range []Backend{BackendInterpreter, BackendJIT} {
+			c := New()
+			c.AddressMode = AddressMode(options & 0x1)
+			c.Quirks = Quirks{
+				Shift: options&0x02 != 0, Jump: options&0x04 != 0, Wrap: options&0x08 != 0,
+				Logic: options&0x10 != 0, DisplayWait: options&0x20 != 0, MemoryIncrementByX: options&0x40 != 0,
+			}
+			c.TickRate = 8
+			if err := c.LoadGame(rom); err != nil {
+				return
+			}
+			runs[backend] = runBackend(c, backend, fuzzTicks/c.TickRate, hold(options>>4, 10, 40))
+		}
+		assertSameRun(t, runs[BackendInterpreter], runs[BackendJIT], "fuzzed program")
+	})
+}
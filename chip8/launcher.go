@@ -0,0 +1,238 @@
+package chip8
+
+import (
+	"fmt"
+	"image/color"
+	"path/filepath"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	launcherKey = sdl.K_ESCAPE
+	resetKey    = sdl.K_F10
+	// launcherBackButton: the gamepad button that opens the launcher during a game
+	launcherBackButton = sdl.CONTROLLER_BUTTON_BACK
+)
+
+// Gamepad2ROMKey: gamepad buttons bound to the key names of the ROM database, like Keyboard2ROMKey
+var Gamepad2ROMKey = map[uint8]string{
+	sdl.CONTROLLER_BUTTON_DPAD_UP: "up", sdl.CONTROLLER_BUTTON_DPAD_DOWN: "down",
+	sdl.CONTROLLER_BUTTON_DPAD_LEFT: "left", sdl.CONTROLLER_BUTTON_DPAD_RIGHT: "right",
+	sdl.CONTROLLER_BUTTON_A: "a", sdl.CONTROLLER_BUTTON_B: "b",
+}
+
+// launcher: the menu listing the ROMs of SDLGraphics.ROMDir, it replaces the
+// chip-8 screen while it's open and the emulation doesn't run
+type launcher struct {
+	visible bool
+	entries []LibraryEntry
+	cursor  int
+	// err is the last error scanning the directory or loading a ROM, shown on the menu
+	err error
+}
+
+// machineDefaults: the settings the machine had before any ROM from the database changed
+// them, restored when switching to a ROM the database doesn't know
+type machineDefaults struct {
+	platform               Platform
+	quirks                 Quirks
+	tickRate               int
+	font                   FontSet
+	fontAddress            uint16
+	foreground, background color.RGBA
+}
+
+// openLauncher: rescans the ROM directory and shows the menu with the current ROM selected
+func (g *SDLGraphics) openLauncher() {
+	l := &g.launcher
+	l.visible = true
+	l.entries, l.err = ScanROMs(g.ROMDir)
+	if l.cursor >= len(l.entries) {
+		l.cursor = 0
+	}
+	if g.c8.ROM == nil {
+		return
+	}
+	for i, entry := range l.entries {
+		if filepath.Base(entry.Path) == g.c8.ROM.Name {
+			l.cursor = i
+		}
+	}
+}
+
+// handleLauncherKey: opens the launcher and resets the game, while the launcher is
+// open it takes every key so they don't reach the chip-8 keyboard
+func (g *SDLGraphics) handleLauncherKey(key sdl.Keycode) bool {
+	l := &g.launcher
+	if !l.visible {
+		switch key {
+		case launcherKey:
+			g.openLauncher()
+		case resetKey:
+			if err := g.controller.Reset(); err == nil {
+				g.memoryPanel.viewer.Forget()
+			}
+		default:
+			return false
+		}
+		return true
+	}
+
+	page := g.launcherRows()
+	switch key {
+	case sdl.K_UP:
+		g.moveLauncherCursor(-1)
+	case sdl.K_DOWN:
+		g.moveLauncherCursor(1)
+	case sdl.K_PAGEUP:
+		g.moveLauncherCursor(-page)
+	case sdl.K_PAGEDOWN:
+		g.moveLauncherCursor(page)
+	case sdl.K_HOME:
+		g.moveLauncherCursor(-len(l.entries))
+	case sdl.K_END:
+		g.moveLauncherCursor(len(l.entries))
+	case sdl.K_RETURN, sdl.K_SPACE:
+		g.startSelectedROM()
+	case sdl.K_r:
+		g.openLauncher()
+	case launcherKey:
+		// Going back is only possible when there's a game to go back to
+		l.visible = g.c8.ROM == nil
+	}
+	return true
+}
+
+// handleLauncherButton: the gamepad version of handleLauncherKey
+func (g *SDLGraphics) handleLauncherButton(button uint8) bool {
+	if !g.launcher.visible {
+		if button != launcherBackButton {
+			return false
+		}
+		g.openLauncher()
+		return true
+	}
+
+	switch button {
+	case sdl.CONTROLLER_BUTTON_DPAD_UP:
+		return g.handleLauncherKey(sdl.K_UP)
+	case sdl.CONTROLLER_BUTTON_DPAD_DOWN:
+		return g.handleLauncherKey(sdl.K_DOWN)
+	case sdl.CONTROLLER_BUTTON_A, sdl.CONTROLLER_BUTTON_START:
+		return g.handleLauncherKey(sdl.K_RETURN)
+	case sdl.CONTROLLER_BUTTON_B, launcherBackButton:
+		return g.handleLauncherKey(launcherKey)
+	}
+	return true
+}
+
+func (g *SDLGraphics) moveLauncherCursor(delta int) {
+	l := &g.launcher
+	l.cursor += delta
+	if l.cursor >= len(l.entries) {
+		l.cursor = len(l.entries) - 1
+	}
+	if l.cursor < 0 {
+		l.cursor = 0
+	}
+}
+
+func (g *SDLGraphics) startSelectedROM() {
+	l := &g.launcher
+	if l.cursor >= len(l.entries) {
+		return
+	}
+	rom, err := LoadROMFile(l.entries[l.cursor].Path)
+	if err != nil {
+		l.err = err
+		return
+	}
+	if !g.UseROMDatabase {
+		rom.Info = nil
+	}
+	g.loadROM(rom)
+}
+
+// loadROM: loads the ROM with the settings the database recommends for it, going back
+// to the defaults for the ones it doesn't know, and closes the launcher
+func (g *SDLGraphics) loadROM(rom *ROM) {
+	g.c8.Platform = g.defaults.platform
+	g.c8.Quirks = g.defaults.quirks
+	g.c8.TickRate = g.defaults.tickRate
+	g.c8.Font, g.c8.FontAddress = g.defaults.font, g.defaults.fontAddress
+	g.display.Foreground = g.defaults.foreground
+	g.display.Background = g.defaults.background
+	if err := g.c8.LoadROM(rom); err != nil {
+		g.launcher.err = err
+		g.launcher.visible = true
+		return
+	}
+	g.applyROMInfo()
+	g.memoryPanel.viewer.Forget()
+	g.launcher.visible = false
+	g.launcher.err = nil
+}
+
+// launcherRows: how many ROMs fit on the window
+func (g *SDLGraphics) launcherRows() int {
+	rows := (g.Height-launcherMargin*2)/g.lineHeight() - 3
+	if rows < 1 {
+		return 1
+	}
+	return rows
+}
+
+const launcherMargin = 20
+
+func (g *SDLGraphics) drawLauncher() error {
+	l := &g.launcher
+	x, y := launcherMargin, launcherMargin
+
+	if err := g.textLine(fmt.Sprintf("ROMs in %s", g.ROMDir), x, &y); err != nil {
+		return err
+	}
+	y += hudPanelSpacing
+
+	rows := g.launcherRows()
+	first := l.cursor - rows/2
+	if first > len(l.entries)-rows {
+		first = len(l.entries) - rows
+	}
+	if first < 0 {
+		first = 0
+	}
+	for i := first; i < len(l.entries) && i < first+rows; i++ {
+		entry := l.entries[i]
+		line := "  " + entry.Title
+		if entry.Info != nil && len(entry.Info.Authors) > 0 {
+			line += fmt.Sprintf(" (%s)", entry.Info.Authors[0])
+		}
+		if i == l.cursor {
+			line = "> " + line[2:]
+			g.selectMainPalette()
+			g.renderer.FillRect(&sdl.Rect{
+				X: int32(x - 4),
+				Y: int32(y),
+				W: int32(g.Width - launcherMargin*2),
+				H: int32(g.lineHeight()),
+			})
+		}
+		if err := g.textLine(line, x, &y); err != nil {
+			return err
+		}
+	}
+	if len(l.entries) == 0 && l.err == nil {
+		if err := g.textLine("No ROMs found", x, &y); err != nil {
+			return err
+		}
+	}
+
+	y = g.Height - launcherMargin - 2*g.lineHeight()
+	if l.err != nil {
+		if err := g.textLine(l.err.Error(), x, &y); err != nil {
+			return err
+		}
+	}
+	return g.textLine("Enter: play  Esc: back  R: rescan  F10: reset game", x, &y)
+}
@@ -0,0 +1,62 @@
+package chip8
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LibraryEntry is a ROM found by ScanROMs
+type LibraryEntry struct {
+	Path  string
+	Title string
+	// Info is what the ROM database knows about the ROM, nil if it isn't there
+	Info *ROMInfo
+}
+
+// romExtensions: the files ScanROMs lists, matching the formats ReadROM understands
+var romExtensions = map[string]bool{
+	".ch8": true,
+	".c8":  true,
+	".sc8": true,
+	".xo8": true,
+	".gif": true,
+}
+
+// ScanROMs: lists the ROMs in the directory sorted by title. Titles come from the ROM
+// database, ROMs that aren't there use the file name without the extension.
+func ScanROMs(dir string) ([]LibraryEntry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	db, err := DefaultDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LibraryEntry, 0, len(files))
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Name()))
+		if file.IsDir() || !romExtensions[ext] {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		entry := LibraryEntry{Path: path, Title: strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))}
+		if data, err := os.ReadFile(path); err == nil {
+			if info, ok := db.Lookup(data); ok {
+				entry.Title, entry.Info = info.Title, info
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if a, b := strings.ToLower(entries[i].Title), strings.ToLower(entries[j].Title); a != b {
+			return a < b
+		}
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
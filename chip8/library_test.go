@@ -0,0 +1,36 @@
+package chip8
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibrary(t *testing.T) {
+	t.Run("ScanROMs should list the ROMs by title", func(t *testing.T) {
+		dir := t.TempDir()
+		pong, err := os.ReadFile("../roms/pong.ch8")
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "PONG.ch8"), pong, 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "alpha.sc8"), []uint8{0x12, 0x00}, 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []uint8("not a ROM"), 0o644))
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, "more.ch8"), 0o755))
+
+		entries, err := ScanROMs(dir)
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 2) {
+			assert.Equal(t, "alpha", entries[0].Title, "Unknown ROMs should be named after the file")
+			assert.Nil(t, entries[0].Info)
+			assert.Equal(t, "Pong", entries[1].Title, "Known ROMs should use the database title")
+			assert.NotNil(t, entries[1].Info)
+			assert.Equal(t, filepath.Join(dir, "PONG.ch8"), entries[1].Path)
+		}
+	})
+
+	t.Run("ScanROMs should fail on missing directories", func(t *testing.T) {
+		_, err := ScanROMs(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
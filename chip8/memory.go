@@ -0,0 +1,146 @@
+package chip8
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	MemorySize         = 0x1000
+	StackSize          = 0x10
+	AddressMask        = MemorySize - 1
+	OpcodeSize  uint16 = 2
+)
+
+// AddressMode decides what happens when an address runs past the end of the 4KB memory
+type AddressMode int
+
+const (
+	// AddressWrap: addresses wrap around to the start of memory, like the 12-bit address bus of the COSMAC VIP
+	AddressWrap AddressMode = iota
+	// AddressFault: the access is refused and the machine stops with a Fault
+	AddressFault
+)
+
+// Access tells why the memory was read
+type Access int
+
+const (
+	AccessFetch Access = iota
+	AccessData
+	AccessSprite
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessFetch:
+		return "fetch"
+	case AccessSprite:
+		return "sprite"
+	}
+	return "data"
+}
+
+var (
+	ErrAddressOutOfRange = errors.New("address out of memory")
+	ErrStackOverflow     = errors.New("stack overflow")
+	ErrStackUnderflow    = errors.New("stack underflow")
+)
+
+// Fault stops the machine, it's kept on Chip8.Fault until a new game is loaded
+type Fault struct {
+	// PC is the address of the instruction that faulted
+	PC uint16
+	// Address is the memory address accessed, or the stack pointer for stack errors
+	Address uint16
+	Err     error
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("fault at PC 0x%03x (address 0x%04x): %v", f.PC, f.Address, f.Err)
+}
+
+func (f *Fault) Unwrap() error {
+	return f.Err
+}
+
+// ReadMemory: reads a byte from the current state, going through the same checks and hooks as the instructions
+func (c *Chip8) ReadMemory(addr uint16) uint8 {
+	return c.read(&c.CurrState, addr, AccessData)
+}
+
+// WriteMemory: writes a byte into the current state, going through the same checks and hooks as the instructions
+func (c *Chip8) WriteMemory(addr uint16, value uint8) {
+	c.write(&c.CurrState, addr, value)
+}
+
+// resolve: applies the address mode, returning false if the access must not happen
+func (c *Chip8) resolve(addr uint16) (uint16, bool) {
+	if addr < MemorySize {
+		return addr, true
+	}
+	if c.AddressMode == AddressFault {
+		c.fault(addr, ErrAddressOutOfRange)
+		return addr, false
+	}
+	return addr & AddressMask, true
+}
+
+func (c *Chip8) read(s *State, addr uint16, access Access) uint8 {
+	addr, ok := c.resolve(addr)
+	if !ok {
+		return 0
+	}
+	value := s.Memory[addr]
+	if c.Hooks.MemoryRead != nil {
+		c.Hooks.MemoryRead(addr, value, access)
+	}
+	return value
+}
+
+func (c *Chip8) write(s *State, addr uint16, value uint8) {
+	addr, ok := c.resolve(addr)
+	if !ok {
+		return
+	}
+	s.Memory[addr] = value
+	c.invalidate(addr)
+	if c.Hooks.MemoryWrite != nil {
+		c.Hooks.MemoryWrite(addr, value)
+	}
+}
+
+// fetch: reads the opcode at PC
+func (c *Chip8) fetch(s *State) uint16 {
+	mostSignificantByte := uint16(c.read(s, s.PC, AccessFetch)) << ByteSize
+	lessSignificantByte := uint16(c.read(s, s.PC+1, AccessFetch))
+	return mostSignificantByte | lessSignificantByte
+}
+
+// push: puts the address on the top of the stack
+func (c *Chip8) push(s *State, addr uint16) bool {
+	if int(s.SP) >= StackSize {
+		c.fault(uint16(s.SP), ErrStackOverflow)
+		return false
+	}
+	s.Stack[s.SP] = addr
+	s.SP++
+	return true
+}
+
+// pop: removes the address on the top of the stack
+func (c *Chip8) pop(s *State) (uint16, bool) {
+	if s.SP == 0 || int(s.SP) > StackSize {
+		c.fault(uint16(s.SP), ErrStackUnderflow)
+		return 0, false
+	}
+	s.SP--
+	return s.Stack[s.SP], true
+}
+
+func (c *Chip8) fault(addr uint16, err error) {
+	if c.Fault != nil {
+		return
+	}
+	c.Fault = &Fault{PC: c.instructionPC, Address: addr, Err: err}
+}
@@ -0,0 +1,110 @@
+package chip8
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	t.Run("Memory should have 4KB and the stack 16 levels", func(t *testing.T) {
+		s := State{}
+		assert.Len(t, s.Memory, 0x1000)
+		assert.Len(t, s.Stack, 16)
+	})
+
+	t.Run("Accesses past the end of memory should wrap around by default", func(t *testing.T) {
+		c := New()
+		c.CurrState.I = 0xFFE
+		c.CurrState.V[0x0] = 0x01
+		c.CurrState.V[0x1] = 0x02
+		c.CurrState.V[0x2] = 0x03
+		newState := c.ExecuteOpcode(0xF255)
+		assert.Equal(t, uint8(0x01), newState.Memory[0xFFE])
+		assert.Equal(t, uint8(0x02), newState.Memory[0xFFF], "Last address should be addressable")
+		assert.Equal(t, uint8(0x03), newState.Memory[0x000], "Should wrap to the start of memory")
+		assert.Nil(t, c.Fault)
+	})
+
+	t.Run("Accesses past the end of memory should fault when asked to", func(t *testing.T) {
+		c := New()
+		c.AddressMode = AddressFault
+		assert.NoError(t, c.LoadGame([]uint8{0xF1, 0x33}))
+		c.CurrState.V[0x1] = 123
+		c.CurrState.I = 0xFFF
+		c.Tick(0)
+		assert.Equal(t, uint8(0x00), c.CurrState.Memory[0x000], "Should not wrap around")
+		assert.True(t, errors.Is(c.Fault, ErrAddressOutOfRange))
+	})
+
+	t.Run("Tick should stop running once the machine faults", func(t *testing.T) {
+		c := New()
+		c.AddressMode = AddressFault
+		c.CurrState.PC = 0xFFF
+		c.Tick(0)
+		assert.True(t, errors.Is(c.Fault, ErrAddressOutOfRange), "Fetching past the end should fault")
+		assert.Equal(t, uint16(0xFFF), c.Fault.(*Fault).PC)
+
+		tickCount := c.TickCount
+		c.Tick(0)
+		assert.Equal(t, tickCount, c.TickCount, "Should not tick while faulted")
+	})
+
+	t.Run("Returning with an empty stack should fault instead of panicking", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadGame([]uint8{0x00, 0xEE}))
+		c.Tick(0)
+		assert.Equal(t, uint8(0x0), c.CurrState.SP)
+		assert.True(t, errors.Is(c.Fault, ErrStackUnderflow))
+	})
+
+	t.Run("Calling with a full stack should fault instead of panicking", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadGame([]uint8{0x23, 0x00}))
+		c.CurrState.SP = StackSize
+		c.Tick(0)
+		assert.Equal(t, uint8(StackSize), c.CurrState.SP)
+		assert.True(t, errors.Is(c.Fault, ErrStackOverflow))
+	})
+
+	t.Run("ExecuteOpcode should leave the machine alone even when the opcode faults", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadGame([]uint8{0x00, 0xE0}))
+		calls := 0
+		c.Hooks.MemoryWrite = func(addr uint16, value uint8) { calls++ }
+		c.Hooks.SpriteDrawn = func(addr uint16, height uint8) { calls++ }
+
+		c.ExecuteOpcode(0x00EE)
+		assert.NoError(t, c.Fault, "The fault should stay on the copy")
+		c.CurrState.I = 0x300
+		c.CurrState.V[0x0] = 1
+		assert.Equal(t, uint8(0x01), c.ExecuteOpcode(0xF033).Memory[0x302])
+		c.ExecuteOpcode(0xD005)
+		assert.Equal(t, 0, calls, "Hooks shouldn't be called")
+
+		c.Tick(0)
+		assert.Equal(t, uint16(ProgramStartAddress+2), c.CurrState.PC, "Tick should still run")
+	})
+
+	t.Run("Hooks should see every read and write with the resolved address", func(t *testing.T) {
+		c := New()
+		var reads []Access
+		var writes []uint16
+		c.Hooks.MemoryRead = func(addr uint16, value uint8, access Access) { reads = append(reads, access) }
+		c.Hooks.MemoryWrite = func(addr uint16, value uint8) { writes = append(writes, addr) }
+
+		c.CurrState.PC = 0x200
+		c.CurrState.Memory[0x200] = 0xF0
+		c.CurrState.Memory[0x201] = 0x33
+		c.CurrState.I = 0xFFF
+		c.Tick(0)
+
+		assert.Equal(t, []Access{AccessFetch, AccessFetch}, reads)
+		assert.Equal(t, []uint16{0xFFF, 0x000, 0x001}, writes)
+
+		c.WriteMemory(0x300, 0x12)
+		assert.Equal(t, uint8(0x12), c.ReadMemory(0x300))
+		assert.Equal(t, AccessData, reads[len(reads)-1])
+	})
+}
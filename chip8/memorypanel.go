@@ -0,0 +1,197 @@
+package chip8
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	memoryPanelToggleKey  = sdl.K_F9
+	memoryPanelSpriteRows = 15
+	memoryPanelPixelSize  = 3
+)
+
+// memoryPanel: hex view of the memory, while the emulation is paused it also
+// edits the byte under the cursor (or the selected register) by typing hex digits
+type memoryPanel struct {
+	visible bool
+	viewer  *MemoryViewer
+
+	// editRegister: index in Registers being edited, or -1 when editing memory
+	editRegister int
+	typedDigits  int
+}
+
+func newMemoryPanel(c8 *Chip8) memoryPanel {
+	viewer := NewMemoryViewer(c8)
+	viewer.BytesPerRow = 8
+	viewer.RowCount = 10
+	return memoryPanel{viewer: viewer, editRegister: -1}
+}
+
+// handleMemoryPanelKey: returns false if the key isn't used by the panel
+func (g *SDLGraphics) handleMemoryPanelKey(key sdl.Keycode) bool {
+	p := &g.memoryPanel
+	switch key {
+	case memoryPanelToggleKey:
+		p.visible = !p.visible
+		return true
+	}
+	if !p.visible {
+		return false
+	}
+
+	v := p.viewer
+	switch key {
+	case sdl.K_UP:
+		v.MoveCursor(-v.BytesPerRow)
+	case sdl.K_DOWN:
+		v.MoveCursor(v.BytesPerRow)
+	case sdl.K_LEFT:
+		v.MoveCursor(-1)
+	case sdl.K_RIGHT:
+		v.MoveCursor(1)
+	case sdl.K_PAGEUP:
+		v.MoveCursor(-v.BytesPerRow * v.RowCount)
+	case sdl.K_PAGEDOWN:
+		v.MoveCursor(v.BytesPerRow * v.RowCount)
+	case sdl.K_HOME:
+		v.GoTo(g.c8.CurrState.PC)
+	case sdl.K_END:
+		v.GoTo(g.c8.CurrState.I)
+	case sdl.K_TAB:
+		if !g.controller.Paused() {
+			return false
+		}
+		p.editRegister++
+		if p.editRegister >= len(Registers) {
+			p.editRegister = -1
+		}
+		p.typedDigits = 0
+	default:
+		digit, ok := hexDigitKey(key)
+		if !ok || !g.controller.Paused() {
+			return false
+		}
+		p.typeDigit(digit)
+	}
+	return true
+}
+
+// typeDigit: shifts the digit into the value being edited
+func (p *memoryPanel) typeDigit(digit uint8) {
+	v := p.viewer
+	if p.editRegister < 0 {
+		value := v.c8.CurrState.Memory[v.Cursor]<<NibbleSize | digit
+		v.Poke(v.Cursor, value)
+		p.typedDigits++
+		if p.typedDigits == 2 {
+			p.typedDigits = 0
+			v.MoveCursor(1)
+		}
+		return
+	}
+
+	name := Registers[p.editRegister]
+	value, _ := v.Register(name)
+	if err := v.SetRegister(name, value<<NibbleSize|uint16(digit)); err != nil {
+		v.SetRegister(name, uint16(digit))
+	}
+}
+
+func (g *SDLGraphics) drawMemoryPanel(x, y int) error {
+	p := &g.memoryPanel
+	if !p.visible {
+		return nil
+	}
+
+	charW, _, err := g.font.SizeUTF8("0")
+	if err != nil {
+		return err
+	}
+	top := y
+
+	for _, row := range p.viewer.Rows() {
+		line := fmt.Sprintf("%03X ", row.Address)
+		for i, cell := range row.Cells {
+			cellRect := &sdl.Rect{
+				X: int32(x + (len(line)+i*3+1)*charW),
+				Y: int32(y),
+				W: int32(2 * charW),
+				H: int32(g.lineHeight()),
+			}
+			switch {
+			case cell.IsPC:
+				g.selectMainPalette()
+				g.renderer.FillRect(cellRect)
+			case cell.IsI:
+				g.renderer.SetDrawColor(60, 120, 220, 255)
+				g.renderer.FillRect(cellRect)
+			case cell.RecentWrite:
+				g.renderer.SetDrawColor(200, 160, 40, 255)
+				g.renderer.FillRect(cellRect)
+			}
+			if cell.IsCursor {
+				g.renderer.SetDrawColor(255, 255, 255, 255)
+				g.renderer.DrawRect(cellRect)
+			}
+		}
+		for _, cell := range row.Cells {
+			line += fmt.Sprintf(" %02X", cell.Value)
+		}
+		if err := g.textLine(line, x, &y); err != nil {
+			return err
+		}
+	}
+
+	status := fmt.Sprintf("Cursor %03X", p.viewer.Cursor)
+	if g.controller.Paused() {
+		target := "memory"
+		if p.editRegister >= 0 {
+			value, _ := p.viewer.Register(Registers[p.editRegister])
+			target = fmt.Sprintf("%s = %X", Registers[p.editRegister], value)
+		}
+		status = fmt.Sprintf("PAUSED  Edit %s", target)
+	}
+	if err := g.textLine(status, x, &y); err != nil {
+		return err
+	}
+
+	return g.drawSpritePreview(x+(4+p.viewer.BytesPerRow*3+2)*charW, top)
+}
+
+// drawSpritePreview: draws the bytes starting at I as a sprite
+func (g *SDLGraphics) drawSpritePreview(x, y int) error {
+	g.selectMainPalette()
+	for row, pixels := range g.memoryPanel.viewer.SpritePreview(memoryPanelSpriteRows) {
+		for col, pixel := range pixels {
+			if pixel != '#' {
+				continue
+			}
+			if err := g.renderer.FillRect(&sdl.Rect{
+				X: int32(x + col*memoryPanelPixelSize),
+				Y: int32(y + row*memoryPanelPixelSize),
+				W: memoryPanelPixelSize,
+				H: memoryPanelPixelSize,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *SDLGraphics) lineHeight() int {
+	return g.font.Height()
+}
+
+func hexDigitKey(key sdl.Keycode) (uint8, bool) {
+	switch {
+	case key >= sdl.K_0 && key <= sdl.K_9:
+		return uint8(key - sdl.K_0), true
+	case key >= sdl.K_a && key <= sdl.K_f:
+		return uint8(key-sdl.K_a) + 0xA, true
+	}
+	return 0, false
+}
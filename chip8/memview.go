@@ -0,0 +1,246 @@
+package chip8
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MemoryViewer is a hex view over the memory of a running Chip8, it keeps
+// track of which bytes were written recently and lets tools poke memory and registers.
+type MemoryViewer struct {
+	// Address is the first address shown
+	Address uint16
+	// Cursor is the selected address
+	Cursor      uint16
+	BytesPerRow int
+	RowCount    int
+	// RecentWriteFrames is for how many calls to Update a written byte stays highlighted
+	RecentWriteFrames int
+
+	c8       *Chip8
+	previous []uint8
+	age      []int
+}
+
+type MemoryCell struct {
+	Address     uint16
+	Value       uint8
+	IsPC        bool
+	IsI         bool
+	IsCursor    bool
+	RecentWrite bool
+}
+
+type MemoryRow struct {
+	Address uint16
+	Cells   []MemoryCell
+}
+
+// Registers: names accepted by SetRegister, in the order they're usually shown
+var Registers = []string{
+	"V0", "V1", "V2", "V3", "V4", "V5", "V6", "V7",
+	"V8", "V9", "VA", "VB", "VC", "VD", "VE", "VF",
+	"I", "PC", "SP", "DT", "ST",
+}
+
+// Update: compares memory with the last call to find out which bytes were written, should be called once per frame
+func (m *MemoryViewer) Update() {
+	memory := m.c8.CurrState.Memory[:]
+	for addr, value := range memory {
+		if value != m.previous[addr] {
+			m.age[addr] = m.RecentWriteFrames
+			m.previous[addr] = value
+		} else if m.age[addr] > 0 {
+			m.age[addr]--
+		}
+	}
+}
+
+// Forget: takes the current memory as the baseline, so nothing is shown as recently written
+func (m *MemoryViewer) Forget() {
+	copy(m.previous, m.c8.CurrState.Memory[:])
+	for addr := range m.age {
+		m.age[addr] = 0
+	}
+}
+
+// Rows: the visible rows, starting from Address
+func (m *MemoryViewer) Rows() []MemoryRow {
+	s := &m.c8.CurrState
+	size := m.size()
+	rows := make([]MemoryRow, 0, m.RowCount)
+	for r := 0; r < m.RowCount; r++ {
+		start := int(m.Address) + r*m.BytesPerRow
+		if start >= size {
+			break
+		}
+		row := MemoryRow{Address: uint16(start)}
+		for addr := start; addr < start+m.BytesPerRow && addr < size; addr++ {
+			row.Cells = append(row.Cells, MemoryCell{
+				Address:     uint16(addr),
+				Value:       s.Memory[addr],
+				IsPC:        addr == int(s.PC) || addr == int(s.PC)+1,
+				IsI:         addr == int(s.I),
+				IsCursor:    addr == int(m.Cursor),
+				RecentWrite: m.age[addr] > 0,
+			})
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// GoTo: moves the cursor to the address, scrolling the view so it's visible
+func (m *MemoryViewer) GoTo(addr uint16) {
+	size := m.size()
+	if int(addr) >= size {
+		addr = uint16(size - 1)
+	}
+	m.Cursor = addr
+
+	page := m.BytesPerRow * m.RowCount
+	rowStart := int(addr) - int(addr)%m.BytesPerRow
+	if rowStart < int(m.Address) {
+		m.Address = uint16(rowStart)
+	} else if rowStart >= int(m.Address)+page {
+		m.Address = uint16(rowStart - page + m.BytesPerRow)
+	}
+}
+
+// MoveCursor: moves the cursor by delta bytes, clamping it to the memory
+func (m *MemoryViewer) MoveCursor(delta int) {
+	addr := int(m.Cursor) + delta
+	if addr < 0 {
+		addr = 0
+	}
+	m.GoTo(uint16(clampAddress(addr, m.size())))
+}
+
+// SpritePreview: renders height bytes starting from I as a sprite, one string per row
+func (m *MemoryViewer) SpritePreview(height int) []string {
+	s := &m.c8.CurrState
+	rows := make([]string, 0, height)
+	for row := 0; row < height; row++ {
+		addr := int(s.I) + row
+		if addr >= m.size() {
+			break
+		}
+		rows = append(rows, spriteRowString(s.Memory[addr]))
+	}
+	return rows
+}
+
+// Poke: writes a byte into memory
+func (m *MemoryViewer) Poke(addr uint16, value uint8) error {
+	if int(addr) >= m.size() {
+		return fmt.Errorf("address 0x%03x is out of memory", addr)
+	}
+	m.c8.CurrState.Memory[addr] = value
+	m.c8.invalidate(addr)
+	return nil
+}
+
+// Register: reads one of the registers listed in Registers
+func (m *MemoryViewer) Register(name string) (uint16, error) {
+	s := &m.c8.CurrState
+	switch name = strings.ToUpper(name); name {
+	case "I":
+		return s.I, nil
+	case "PC":
+		return s.PC, nil
+	case "SP":
+		return uint16(s.SP), nil
+	case "DT":
+		return uint16(s.DelayTimer), nil
+	case "ST":
+		return uint16(s.SoundTimer), nil
+	}
+	if reg, ok := vRegister(name); ok {
+		return uint16(s.V[reg]), nil
+	}
+	return 0, fmt.Errorf("unknown register %q", name)
+}
+
+// SetRegister: writes one of the registers listed in Registers, the value must fit in the register
+func (m *MemoryViewer) SetRegister(name string, value uint16) error {
+	s := &m.c8.CurrState
+	max := uint16(0xFF)
+	switch name = strings.ToUpper(name); name {
+	case "I", "PC":
+		max = uint16(m.size() - 1)
+	case "SP":
+		max = uint16(len(s.Stack))
+	}
+	if value > max {
+		return fmt.Errorf("value 0x%x doesn't fit in %s", value, name)
+	}
+
+	switch name {
+	case "I":
+		s.I = value
+	case "PC":
+		s.PC = value
+	case "SP":
+		s.SP = uint8(value)
+	case "DT":
+		s.DelayTimer = uint8(value)
+	case "ST":
+		s.SoundTimer = uint8(value)
+	default:
+		reg, ok := vRegister(name)
+		if !ok {
+			return fmt.Errorf("unknown register %q", name)
+		}
+		s.V[reg] = uint8(value)
+	}
+	return nil
+}
+
+func (m *MemoryViewer) size() int {
+	return len(m.c8.CurrState.Memory)
+}
+
+func vRegister(name string) (uint8, bool) {
+	var reg uint8
+	if len(name) != 2 || name[0] != 'V' {
+		return 0, false
+	}
+	if _, err := fmt.Sscanf(name[1:], "%X", &reg); err != nil {
+		return 0, false
+	}
+	return reg, true
+}
+
+func clampAddress(addr, size int) int {
+	if addr >= size {
+		return size - 1
+	}
+	return addr
+}
+
+func spriteRowString(value uint8) string {
+	var row strings.Builder
+	for col := 0; col < 8; col++ {
+		if value&(FirstFontBitMask>>col) != 0 {
+			row.WriteByte('#')
+		} else {
+			row.WriteByte('.')
+		}
+	}
+	return row.String()
+}
+
+func NewMemoryViewer(c8 *Chip8) *MemoryViewer {
+	m := &MemoryViewer{
+		Address:           ProgramStartAddress,
+		Cursor:            ProgramStartAddress,
+		BytesPerRow:       16,
+		RowCount:          8,
+		RecentWriteFrames: 30,
+		c8:                c8,
+		previous:          make([]uint8, len(c8.CurrState.Memory)),
+		age:               make([]int, len(c8.CurrState.Memory)),
+	}
+	m.Forget()
+	return m
+}
@@ -0,0 +1,73 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryViewer(t *testing.T) {
+	t.Run("Rows should highlight PC, I and the cursor", func(t *testing.T) {
+		c := New()
+		c.CurrState.PC = 0x202
+		c.CurrState.I = 0x205
+		m := NewMemoryViewer(c)
+		m.GoTo(0x201)
+
+		cells := m.Rows()[0].Cells
+		assert.Equal(t, uint16(0x200), m.Rows()[0].Address, "First row should start at the program start address")
+		assert.True(t, cells[2].IsPC && cells[3].IsPC, "Both bytes of the opcode at PC should be highlighted")
+		assert.True(t, cells[5].IsI, "Byte at I should be highlighted")
+		assert.True(t, cells[1].IsCursor, "Byte under the cursor should be highlighted")
+	})
+
+	t.Run("Update should highlight recent writes until they get old", func(t *testing.T) {
+		c := New()
+		m := NewMemoryViewer(c)
+		m.RecentWriteFrames = 2
+
+		c.CurrState.Memory[0x200] = 0xAB
+		m.Update()
+		assert.True(t, m.Rows()[0].Cells[0].RecentWrite, "Written byte should be highlighted")
+		m.Update()
+		m.Update()
+		assert.False(t, m.Rows()[0].Cells[0].RecentWrite, "Byte should not be highlighted anymore")
+	})
+
+	t.Run("GoTo should scroll the view to the address", func(t *testing.T) {
+		m := NewMemoryViewer(New())
+		m.GoTo(0x400)
+		assert.Equal(t, uint16(0x400), m.Cursor)
+		assert.Equal(t, uint16(0x390), m.Address, "Address should be on the last visible row")
+		m.GoTo(0x100)
+		assert.Equal(t, uint16(0x100), m.Address, "Address should be on the first visible row")
+	})
+
+	t.Run("SpritePreview should render the bytes at I", func(t *testing.T) {
+		c := New()
+		c.LoadFonts()
+		c.CurrState.I = FontsStartAddress
+		m := NewMemoryViewer(c)
+		assert.Equal(t, []string{"####....", "#..#....", "#..#....", "#..#....", "####...."}, m.SpritePreview(5))
+	})
+
+	t.Run("Poke and SetRegister should edit the machine", func(t *testing.T) {
+		c := New()
+		m := NewMemoryViewer(c)
+
+		assert.NoError(t, m.Poke(0x300, 0x12))
+		assert.Equal(t, uint8(0x12), c.CurrState.Memory[0x300])
+		assert.Error(t, m.Poke(0x1000, 0x12), "Should not write out of memory")
+
+		assert.NoError(t, m.SetRegister("vA", 0x34))
+		assert.Equal(t, uint8(0x34), c.CurrState.V[0xA])
+		assert.NoError(t, m.SetRegister("I", 0x345))
+		assert.Equal(t, uint16(0x345), c.CurrState.I)
+		assert.Error(t, m.SetRegister("DT", 0x100), "Should not overflow the register")
+		assert.Error(t, m.SetRegister("VG", 0x1), "Should not accept unknown registers")
+
+		value, err := m.Register("I")
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(0x345), value)
+	})
+}
@@ -0,0 +1,782 @@
+// Package octo assembles the programs of Octo (https://github.com/JohnEarnest/Octo), the language
+// Octo cartridges carry their programs in, to chip-8 binaries. It knows the chip-8 subset of the
+// language: the statements of every chip-8 instruction, labels, if/then, if/begin/else/end,
+// loop/again with while, the comparison pseudo-ops, :const, :alias, :org, :next, :unpack, :byte,
+// :call, :calc and :macro. The SCHIP and XO-CHIP statements are reported as errors.
+package octo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// StartAddress: where Octo programs are loaded, the address of the first byte assembled
+	StartAddress = 0x200
+	memorySize   = 0x1000
+)
+
+// Error is a problem with the source, on the line it was found
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+type token struct {
+	text string
+	line int
+}
+
+// tokenize: splits the source on whitespace, dropping the # comments
+func tokenize(source string) []token {
+	var tokens []token
+	for n, line := range strings.Split(source, "\n") {
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+		for _, text := range strings.Fields(line) {
+			tokens = append(tokens, token{text, n + 1})
+		}
+	}
+	return tokens
+}
+
+// fixupKind: how a label that wasn't defined yet is written once it is
+type fixupKind int
+
+const (
+	// fixupAddress: the 12 bits of nnn in the opcode
+	fixupAddress fixupKind = iota
+	// fixupHighNibble: the top 4 bits of the address in the low nibble of the second byte, for :unpack
+	fixupHighNibble
+	// fixupLowByte: the low 8 bits of the address in the second byte, for :unpack
+	fixupLowByte
+)
+
+type fixup struct {
+	addr  int
+	label string
+	kind  fixupKind
+	line  int
+}
+
+// blockKind: the structures that are closed later on
+type blockKind int
+
+const (
+	blockIf blockKind = iota
+	blockElse
+	blockLoop
+)
+
+type block struct {
+	kind blockKind
+	// addr is the start of a loop, or the jump of if and else to patch
+	addr int
+	// whiles are the jumps out of a loop to patch on again
+	whiles []int
+	line   int
+}
+
+type macro struct {
+	params []string
+	body   []token
+}
+
+type assembler struct {
+	tokens  []token
+	pos     int
+	line    int
+	rom     [memorySize]uint8
+	pc, end int
+	labels  map[string]int
+	consts  map[string]int
+	aliases map[string]uint8
+	macros  map[string]*macro
+	fixups  []fixup
+	blocks  []*block
+}
+
+// unsupported: the SCHIP and XO-CHIP statements
+var unsupported = map[string]bool{
+	"hires": true, "lores": true, "scroll-down": true, "scroll-up": true, "scroll-left": true,
+	"scroll-right": true, "exit": true, "saveflags": true, "loadflags": true, "plane": true,
+	"audio": true, "pitch": true, ":stringmode": true, ":pointer": true,
+}
+
+// Assemble: the binary of the program, to be loaded at StartAddress. Like Octo, the program
+// starts with a jump to the main label unless the source starts with it.
+func Assemble(source string) ([]uint8, error) {
+	a := &assembler{
+		tokens:  tokenize(source),
+		pc:      StartAddress,
+		end:     StartAddress,
+		labels:  map[string]int{},
+		consts:  map[string]int{},
+		aliases: map[string]uint8{},
+		macros:  map[string]*macro{},
+	}
+	if err := a.assemble(); err != nil {
+		return nil, err
+	}
+	return a.rom[StartAddress:a.end], nil
+}
+
+func (a *assembler) assemble() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+
+	if len(a.tokens) < 2 || a.tokens[0].text != ":" || a.tokens[1].text != "main" {
+		a.emitAddress(0x1000, "main")
+	}
+	for a.pos < len(a.tokens) {
+		a.statement()
+	}
+	if len(a.blocks) > 0 {
+		b := a.blocks[len(a.blocks)-1]
+		a.line = b.line
+		a.fail("%s isn't closed", map[blockKind]string{blockIf: "if", blockElse: "else", blockLoop: "loop"}[b.kind])
+	}
+	if _, ok := a.labels["main"]; !ok {
+		a.line = 1
+		a.fail("the program has no main label")
+	}
+	for _, f := range a.fixups {
+		addr, ok := a.labels[f.label]
+		if !ok {
+			a.line = f.line
+			a.fail("undefined label %q", f.label)
+		}
+		a.line = f.line
+		a.patch(f.addr, addr, f.kind)
+	}
+	return nil
+}
+
+func (a *assembler) fail(format string, args ...interface{}) {
+	panic(&Error{Line: a.line, Message: fmt.Sprintf(format, args...)})
+}
+
+// next: the next token, expanding the macros
+func (a *assembler) next() string {
+	for {
+		if a.pos >= len(a.tokens) {
+			a.fail("the program ends in the middle of a statement")
+		}
+		t := a.tokens[a.pos]
+		a.pos++
+		a.line = t.line
+		m, ok := a.macros[t.text]
+		if !ok {
+			return t.text
+		}
+		a.expand(m)
+	}
+}
+
+// peek: the next token without taking it, "" at the end
+func (a *assembler) peek() string {
+	if a.pos >= len(a.tokens) {
+		return ""
+	}
+	return a.tokens[a.pos].text
+}
+
+func (a *assembler) expect(text string) {
+	if got := a.next(); got != text {
+		a.fail("expected %q, got %q", text, got)
+	}
+}
+
+// expand: replaces the macro call with its body, with the arguments in place of the parameters
+func (a *assembler) expand(m *macro) {
+	args := map[string]string{}
+	for _, param := range m.params {
+		args[param] = a.next()
+	}
+	body := make([]token, len(m.body))
+	for i, t := range m.body {
+		if arg, ok := args[t.text]; ok {
+			t.text = arg
+		}
+		body[i] = t
+	}
+	rest := append(body, a.tokens[a.pos:]...)
+	a.tokens = append(a.tokens[:a.pos], rest...)
+}
+
+func (a *assembler) emit(bytes ...uint8) {
+	for _, b := range bytes {
+		if a.pc >= memorySize {
+			a.fail("the program doesn't fit in memory")
+		}
+		a.rom[a.pc] = b
+		a.pc++
+	}
+	if a.pc > a.end {
+		a.end = a.pc
+	}
+}
+
+func (a *assembler) emitOpcode(opcode uint16) {
+	a.emit(uint8(opcode>>8), uint8(opcode))
+}
+
+// emitAddress: emits the opcode with the address of the label, now or once it's defined
+func (a *assembler) emitAddress(opcode uint16, label string) {
+	if addr, ok := a.labels[label]; ok {
+		a.emitOpcode(opcode)
+		a.patch(a.pc-2, addr, fixupAddress)
+		return
+	}
+	a.fixups = append(a.fixups, fixup{addr: a.pc, label: label, kind: fixupAddress, line: a.line})
+	a.emitOpcode(opcode)
+}
+
+func (a *assembler) patch(at, addr int, kind fixupKind) {
+	switch kind {
+	case fixupAddress:
+		if addr < 0 || addr >= memorySize {
+			a.fail("address 0x%X doesn't fit in 12 bits", addr)
+		}
+		a.rom[at] = a.rom[at]&0xF0 | uint8(addr>>8)
+		a.rom[at+1] = uint8(addr)
+	case fixupHighNibble:
+		a.rom[at+1] |= uint8(addr>>8) & 0x0F
+	case fixupLowByte:
+		a.rom[at+1] = uint8(addr)
+	}
+}
+
+// address: emits the opcode with the address the token names, a number, a constant or a label
+func (a *assembler) address(opcode uint16) {
+	text := a.next()
+	if value, ok := a.constant(text); ok {
+		if value < 0 || value >= memorySize {
+			a.fail("address %s is out of memory", text)
+		}
+		a.emitOpcode(opcode | uint16(value))
+		return
+	}
+	a.emitAddress(opcode, text)
+}
+
+// constant: the value of a number, a constant or a label already defined
+func (a *assembler) constant(text string) (int, bool) {
+	if value, err := strconv.ParseInt(text, 0, 32); err == nil {
+		return int(value), true
+	}
+	if value, ok := a.consts[text]; ok {
+		return value, true
+	}
+	if value, ok := a.labels[text]; ok {
+		return value, true
+	}
+	if text == "{" {
+		return a.calc(), true
+	}
+	return 0, false
+}
+
+// byteValue: a value that fits in a byte, negative ones taken as two's complement
+func (a *assembler) byteValue() uint8 {
+	text := a.next()
+	value, ok := a.constant(text)
+	if !ok {
+		a.fail("expected a number, got %q", text)
+	}
+	if value < -128 || value > 0xFF {
+		a.fail("%s doesn't fit in a byte", text)
+	}
+	return uint8(value)
+}
+
+func (a *assembler) nibble() uint16 {
+	text := a.next()
+	value, ok := a.constant(text)
+	if !ok || value < 0 || value > 0xF {
+		a.fail("expected a number from 0 to 15, got %q", text)
+	}
+	return uint16(value)
+}
+
+// register: the number of the register the token names, false if it isn't one
+func (a *assembler) register(text string) (uint8, bool) {
+	if r, ok := a.aliases[text]; ok {
+		return r, true
+	}
+	if len(text) == 2 && (text[0] == 'v' || text[0] == 'V') {
+		if r, err := strconv.ParseUint(text[1:], 16, 8); err == nil {
+			return uint8(r), true
+		}
+	}
+	return 0, false
+}
+
+func (a *assembler) expectRegister() uint16 {
+	text := a.next()
+	r, ok := a.register(text)
+	if !ok {
+		a.fail("expected a register, got %q", text)
+	}
+	return uint16(r)
+}
+
+func (a *assembler) statement() {
+	text := a.next()
+	if unsupported[text] {
+		a.fail("%s is a SCHIP or XO-CHIP statement, only chip-8 is supported", text)
+	}
+	if x, ok := a.register(text); ok {
+		a.registerStatement(uint16(x))
+		return
+	}
+
+	switch text {
+	case ":":
+		a.label(a.next(), a.pc)
+	case ":next":
+		a.label(a.next(), a.pc+1)
+	case ":const":
+		name := a.next()
+		value, ok := a.constant(a.next())
+		if !ok {
+			a.fail("the value of constant %s isn't known", name)
+		}
+		a.consts[name] = value
+	case ":alias":
+		name := a.next()
+		a.aliases[name] = uint8(a.expectRegister())
+	case ":org":
+		value, ok := a.constant(a.next())
+		if !ok || value < 0 || value >= memorySize {
+			a.fail(":org needs an address in memory")
+		}
+		a.pc = value
+	case ":byte":
+		a.emit(a.byteValue())
+	case ":call":
+		a.address(0x2000)
+	case ":unpack":
+		a.unpack()
+	case ":calc":
+		name := a.next()
+		a.expect("{")
+		a.consts[name] = a.calc()
+	case ":macro":
+		a.defineMacro()
+	case ":breakpoint":
+		a.next()
+	case ":monitor":
+		a.next()
+		a.next()
+	case "clear":
+		a.emitOpcode(0x00E0)
+	case "return", ";":
+		a.emitOpcode(0x00EE)
+	case "jump":
+		a.address(0x1000)
+	case "jump0":
+		a.address(0xB000)
+	case "i":
+		a.indexStatement()
+	case "delay":
+		a.expect(":=")
+		a.emitOpcode(0xF015 | a.expectRegister()<<8)
+	case "buzzer":
+		a.expect(":=")
+		a.emitOpcode(0xF018 | a.expectRegister()<<8)
+	case "sprite":
+		x, y := a.expectRegister(), a.expectRegister()
+		a.emitOpcode(0xD000 | x<<8 | y<<4 | a.nibble())
+	case "bcd":
+		a.emitOpcode(0xF033 | a.expectRegister()<<8)
+	case "save", "load":
+		x := a.expectRegister()
+		if a.peek() == "-" {
+			a.fail("%s with a range of registers is an XO-CHIP statement, only chip-8 is supported", text)
+		}
+		if text == "save" {
+			a.emitOpcode(0xF055 | x<<8)
+		} else {
+			a.emitOpcode(0xF065 | x<<8)
+		}
+	case "if":
+		a.ifStatement()
+	case "else":
+		a.elseStatement()
+	case "end":
+		b := a.closeBlock("end", blockIf, blockElse)
+		a.patchJump(b.addr, a.pc)
+	case "loop":
+		a.blocks = append(a.blocks, &block{kind: blockLoop, addr: a.pc, line: a.line})
+	case "while":
+		a.whileStatement()
+	case "again":
+		b := a.closeBlock("again", blockLoop)
+		a.emitOpcode(0x1000 | uint16(b.addr))
+		for _, addr := range b.whiles {
+			a.patchJump(addr, a.pc)
+		}
+	default:
+		if value, err := strconv.ParseInt(text, 0, 32); err == nil {
+			if value < -128 || value > 0xFF {
+				a.fail("%s doesn't fit in a byte", text)
+			}
+			a.emit(uint8(value))
+			return
+		}
+		if value, ok := a.consts[text]; ok {
+			a.emit(uint8(value))
+			return
+		}
+		if strings.HasPrefix(text, ":") || text == "{" || text == "}" {
+			a.fail("unknown directive %q", text)
+		}
+		// any other name is a call to the subroutine at the label
+		a.emitAddress(0x2000, text)
+	}
+}
+
+func (a *assembler) label(name string, addr int) {
+	if _, ok := a.labels[name]; ok {
+		a.fail("label %s is defined twice", name)
+	}
+	a.labels[name] = addr
+}
+
+// registerStatement: the statements that start with Vx
+func (a *assembler) registerStatement(x uint16) {
+	op := a.next()
+	if op == ":=" {
+		switch a.peek() {
+		case "delay":
+			a.next()
+			a.emitOpcode(0xF007 | x<<8)
+			return
+		case "key":
+			a.next()
+			a.emitOpcode(0xF00A | x<<8)
+			return
+		case "random":
+			a.next()
+			a.emitOpcode(0xC000 | x<<8 | uint16(a.byteValue()))
+			return
+		}
+	}
+
+	registerOps := map[string]uint16{":=": 0x0, "|=": 0x1, "&=": 0x2, "^=": 0x3, "+=": 0x4, "-=": 0x5, ">>=": 0x6, "=-": 0x7, "<<=": 0xE}
+	code, ok := registerOps[op]
+	if !ok {
+		a.fail("unknown operator %q", op)
+	}
+	if y, ok := a.register(a.peek()); ok {
+		a.next()
+		a.emitOpcode(0x8000 | x<<8 | uint16(y)<<4 | code)
+		return
+	}
+	switch op {
+	case ":=":
+		a.emitOpcode(0x6000 | x<<8 | uint16(a.byteValue()))
+	case "+=":
+		a.emitOpcode(0x7000 | x<<8 | uint16(a.byteValue()))
+	case "-=":
+		a.emitOpcode(0x7000 | x<<8 | uint16(-a.byteValue()))
+	default:
+		a.fail("%s needs a register", op)
+	}
+}
+
+// indexStatement: the statements that start with i
+func (a *assembler) indexStatement() {
+	switch op := a.next(); op {
+	case ":=":
+		switch a.peek() {
+		case "hex":
+			a.next()
+			a.emitOpcode(0xF029 | a.expectRegister()<<8)
+		case "bighex", "long":
+			a.fail("i := %s is a SCHIP or XO-CHIP statement, only chip-8 is supported", a.next())
+		default:
+			a.address(0xA000)
+		}
+	case "+=":
+		a.emitOpcode(0xF01E | a.expectRegister()<<8)
+	default:
+		a.fail("unknown operator %q for i", op)
+	}
+}
+
+// unpack: :unpack n label loads v0 with n and the top nibble of the address, and v1 with the rest
+func (a *assembler) unpack() {
+	high := a.nibble()
+	label := a.next()
+	if addr, ok := a.constant(label); ok {
+		a.emitOpcode(0x6000 | high<<4 | uint16(addr>>8)&0x0F)
+		a.emitOpcode(0x6100 | uint16(addr)&0xFF)
+		return
+	}
+	a.fixups = append(a.fixups, fixup{addr: a.pc, label: label, kind: fixupHighNibble, line: a.line})
+	a.emitOpcode(0x6000 | high<<4)
+	a.fixups = append(a.fixups, fixup{addr: a.pc, label: label, kind: fixupLowByte, line: a.line})
+	a.emitOpcode(0x6100)
+}
+
+func (a *assembler) defineMacro() {
+	name := a.next()
+	m := &macro{}
+	for {
+		text := a.next()
+		if text == "{" {
+			break
+		}
+		m.params = append(m.params, text)
+	}
+	// the body is taken as it is, the macros in it expand where it's used
+	depth := 1
+	for ; a.pos < len(a.tokens); a.pos++ {
+		t := a.tokens[a.pos]
+		if t.text == "{" {
+			depth++
+		} else if t.text == "}" {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+		m.body = append(m.body, t)
+	}
+	if depth != 0 {
+		a.fail("macro %s isn't closed", name)
+	}
+	a.pos++
+	a.macros[name] = m
+}
+
+// condition: the instructions that work out the condition, and the skips taken when it's
+// true and when it's false. The comparisons use vf like Octo does.
+func (a *assembler) condition() (prelude []uint16, skipIfTrue, skipIfFalse uint16) {
+	x := a.expectRegister()
+	op := a.next()
+	switch op {
+	case "key":
+		return nil, 0xE09E | x<<8, 0xE0A1 | x<<8
+	case "-key":
+		return nil, 0xE0A1 | x<<8, 0xE09E | x<<8
+	}
+
+	text := a.next()
+	y, isRegister := a.register(text)
+	var value int
+	if !isRegister {
+		var ok bool
+		if value, ok = a.constant(text); !ok || value < -128 || value > 0xFF {
+			a.fail("expected a register or a byte, got %q", text)
+		}
+		value &= 0xFF
+	}
+
+	switch op {
+	case "==", "!=":
+		equal, notEqual := 0x3000|x<<8|uint16(value), 0x4000|x<<8|uint16(value)
+		if isRegister {
+			equal, notEqual = 0x5000|x<<8|uint16(y)<<4, 0x9000|x<<8|uint16(y)<<4
+		}
+		if op == "==" {
+			return nil, equal, notEqual
+		}
+		return nil, notEqual, equal
+	}
+
+	// vf := rhs, vf =- vx leaves vx >= rhs in vf; vf := vx, vf =- vy leaves vy >= vx
+	atLeast := func(rhs int) []uint16 {
+		if rhs < 0 {
+			return []uint16{0x8F00 | uint16(y)<<4, 0x8F07 | x<<4}
+		}
+		return []uint16{0x6F00 | uint16(rhs), 0x8F07 | x<<4}
+	}
+	rhs := value
+	if isRegister {
+		rhs = -1
+	}
+	whenSet := func(prelude []uint16) ([]uint16, uint16, uint16) { return prelude, 0x3F01, 0x4F01 }
+	whenClear := func(prelude []uint16) ([]uint16, uint16, uint16) { return prelude, 0x3F00, 0x4F00 }
+
+	switch op {
+	case ">=":
+		return whenSet(atLeast(rhs))
+	case "<":
+		return whenClear(atLeast(rhs))
+	case ">", "<=":
+		var prelude []uint16
+		if isRegister {
+			// vy >= vx, the other way around
+			prelude = []uint16{0x8F00 | x<<4, 0x8F07 | uint16(y)<<4}
+		} else {
+			if value == 0xFF {
+				a.fail("%s 255 is always %v", op, op == "<=")
+			}
+			// vx > n is vx >= n+1
+			prelude = atLeast(value + 1)
+			if op == ">" {
+				return whenSet(prelude)
+			}
+			return whenClear(prelude)
+		}
+		if op == ">" {
+			return whenClear(prelude)
+		}
+		return whenSet(prelude)
+	}
+	a.fail("unknown comparison %q", op)
+	return nil, 0, 0
+}
+
+func (a *assembler) ifStatement() {
+	line := a.line
+	prelude, skipIfTrue, skipIfFalse := a.condition()
+	for _, opcode := range prelude {
+		a.emitOpcode(opcode)
+	}
+	switch a.next() {
+	case "then":
+		a.emitOpcode(skipIfFalse)
+		a.statement()
+	case "begin":
+		a.emitOpcode(skipIfTrue)
+		a.blocks = append(a.blocks, &block{kind: blockIf, addr: a.pc, line: line})
+		a.emitOpcode(0x1000)
+	default:
+		a.fail("expected then or begin after the condition")
+	}
+}
+
+func (a *assembler) elseStatement() {
+	b := a.closeBlock("else", blockIf)
+	a.blocks = append(a.blocks, &block{kind: blockElse, addr: a.pc, line: a.line})
+	a.emitOpcode(0x1000)
+	a.patchJump(b.addr, a.pc)
+}
+
+func (a *assembler) whileStatement() {
+	var loop *block
+	for i := len(a.blocks) - 1; i >= 0 && loop == nil; i-- {
+		if a.blocks[i].kind == blockLoop {
+			loop = a.blocks[i]
+		}
+	}
+	if loop == nil {
+		a.fail("while outside of a loop")
+	}
+	prelude, skipIfTrue, _ := a.condition()
+	for _, opcode := range prelude {
+		a.emitOpcode(opcode)
+	}
+	a.emitOpcode(skipIfTrue)
+	loop.whiles = append(loop.whiles, a.pc)
+	a.emitOpcode(0x1000)
+}
+
+// closeBlock: takes the innermost block, which has to be one of the kinds
+func (a *assembler) closeBlock(name string, kinds ...blockKind) *block {
+	if len(a.blocks) > 0 {
+		b := a.blocks[len(a.blocks)-1]
+		for _, kind := range kinds {
+			if b.kind == kind {
+				a.blocks = a.blocks[:len(a.blocks)-1]
+				return b
+			}
+		}
+	}
+	a.fail("%s without the start of its block", name)
+	return nil
+}
+
+func (a *assembler) patchJump(at, target int) {
+	a.patch(at, target, fixupAddress)
+}
+
+// calc: evaluates the expression up to the closing brace, with the usual precedence
+func (a *assembler) calc() int {
+	value := a.binary(0)
+	a.expect("}")
+	return value
+}
+
+// calcPrecedence: the binary operators of :calc, the higher the tighter
+var calcPrecedence = map[string]int{
+	"|": 1, "^": 2, "&": 3, "<<": 4, ">>": 4, "+": 5, "-": 5, "*": 6, "/": 6, "%": 6,
+}
+
+func (a *assembler) binary(min int) int {
+	left := a.unary()
+	for {
+		op := a.peek()
+		precedence, ok := calcPrecedence[op]
+		if !ok || precedence <= min {
+			return left
+		}
+		a.next()
+		right := a.binary(precedence)
+		switch op {
+		case "|":
+			left |= right
+		case "^":
+			left ^= right
+		case "&":
+			left &= right
+		case "<<":
+			left <<= uint(right)
+		case ">>":
+			left >>= uint(right)
+		case "+":
+			left += right
+		case "-":
+			left -= right
+		case "*":
+			left *= right
+		case "/", "%":
+			if right == 0 {
+				a.fail("division by zero")
+			}
+			if op == "/" {
+				left /= right
+			} else {
+				left %= right
+			}
+		}
+	}
+}
+
+func (a *assembler) unary() int {
+	text := a.next()
+	switch text {
+	case "-":
+		return -a.unary()
+	case "~":
+		return ^a.unary()
+	case "(":
+		value := a.binary(0)
+		a.expect(")")
+		return value
+	case "HERE":
+		return a.pc
+	}
+	value, ok := a.constant(text)
+	if !ok {
+		a.fail("%q has no value yet", text)
+	}
+	return value
+}
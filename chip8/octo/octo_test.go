@@ -0,0 +1,162 @@
+package octo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssemble(t *testing.T) {
+	t.Run("Assemble should encode every chip-8 statement", func(t *testing.T) {
+		cases := map[string][]uint8{
+			"clear":            {0x00, 0xE0},
+			"return":           {0x00, 0xEE},
+			";":                {0x00, 0xEE},
+			"jump 0x234":       {0x12, 0x34},
+			"jump0 0x234":      {0xB2, 0x34},
+			":call 0x234":      {0x22, 0x34},
+			"v1 := 0x2A":       {0x61, 0x2A},
+			"v1 += 3":          {0x71, 0x03},
+			"v1 -= 3":          {0x71, 0xFD},
+			"v1 := v2":         {0x81, 0x20},
+			"v1 |= v2":         {0x81, 0x21},
+			"v1 &= v2":         {0x81, 0x22},
+			"v1 ^= v2":         {0x81, 0x23},
+			"v1 += v2":         {0x81, 0x24},
+			"v1 -= v2":         {0x81, 0x25},
+			"v1 >>= v2":        {0x81, 0x26},
+			"v1 =- v2":         {0x81, 0x27},
+			"v1 <<= v2":        {0x81, 0x2E},
+			"vA := random 0xF": {0xCA, 0x0F},
+			"v1 := delay":      {0xF1, 0x07},
+			"v1 := key":        {0xF1, 0x0A},
+			"delay := v1":      {0xF1, 0x15},
+			"buzzer := v1":     {0xF1, 0x18},
+			"i := 0x234":       {0xA2, 0x34},
+			"i += v1":          {0xF1, 0x1E},
+			"i := hex v1":      {0xF1, 0x29},
+			"bcd v1":           {0xF1, 0x33},
+			"save v1":          {0xF1, 0x55},
+			"load v1":          {0xF1, 0x65},
+			"sprite v1 v2 5":   {0xD1, 0x25},
+			"0x12 -1 255":      {0x12, 0xFF, 0xFF},
+			":byte 7":          {0x07},
+		}
+		for statement, want := range cases {
+			rom, err := Assemble(": main " + statement)
+			assert.NoError(t, err, statement)
+			assert.Equal(t, want, rom, statement)
+		}
+	})
+
+	t.Run("Assemble should resolve labels defined before and after they're used", func(t *testing.T) {
+		rom, err := Assemble(`
+			: main
+				i := sprite   # forward
+				draw
+			: loop-forever
+				jump loop-forever
+			: draw
+				sprite v0 v0 1
+				return
+			: sprite
+				0x80`)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint8{0xA2, 0x0A, 0x22, 0x06, 0x12, 0x04, 0xD0, 0x01, 0x00, 0xEE, 0x80}, rom)
+	})
+
+	t.Run("Assemble should start with a jump to main when it isn't first", func(t *testing.T) {
+		rom, err := Assemble(": data 0x01 0x02 : main jump main")
+		assert.NoError(t, err)
+		assert.Equal(t, []uint8{0x12, 0x04, 0x01, 0x02, 0x12, 0x04}, rom)
+	})
+
+	t.Run("Assemble should compile if, else, loop and while to skips and jumps", func(t *testing.T) {
+		rom, err := Assemble(`
+			: main
+				if v0 == 1 then v1 := 2
+				if v0 != v1 begin
+					v2 := 3
+				else
+					v2 := 4
+				end
+				loop
+					v3 += 1
+					while v3 != 5
+					if v3 key then v4 := 1
+				again`)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint8{
+			0x40, 0x01, // 200 skip when v0 != 1
+			0x61, 0x02, // 202
+			0x90, 0x10, // 204 skip the jump when v0 != v1
+			0x12, 0x0C, // 206 jump else
+			0x62, 0x03, // 208
+			0x12, 0x0E, // 20A jump end
+			0x62, 0x04, // 20C else
+			0x73, 0x01, // 20E loop
+			0x43, 0x05, // 210 skip the jump while v3 != 5
+			0x12, 0x1A, // 212 jump past again
+			0xE3, 0xA1, // 214 skip when v3 isn't pressed
+			0x64, 0x01, // 216
+			0x12, 0x0E, // 218 again
+		}, rom)
+	})
+
+	t.Run("Assemble should compare with vf", func(t *testing.T) {
+		cases := map[string][]uint8{
+			"if v1 >= v2 then": {0x8F, 0x20, 0x8F, 0x17, 0x4F, 0x01},
+			"if v1 < v2 then":  {0x8F, 0x20, 0x8F, 0x17, 0x4F, 0x00},
+			"if v1 > v2 then":  {0x8F, 0x10, 0x8F, 0x27, 0x4F, 0x00},
+			"if v1 <= v2 then": {0x8F, 0x10, 0x8F, 0x27, 0x4F, 0x01},
+			"if v1 >= 5 then":  {0x6F, 0x05, 0x8F, 0x17, 0x4F, 0x01},
+			"if v1 > 5 then":   {0x6F, 0x06, 0x8F, 0x17, 0x4F, 0x01},
+			"if v1 <= 5 then":  {0x6F, 0x06, 0x8F, 0x17, 0x4F, 0x00},
+		}
+		for condition, want := range cases {
+			rom, err := Assemble(": main " + condition + " clear")
+			assert.NoError(t, err, condition)
+			assert.Equal(t, append(want, 0x00, 0xE0), rom, condition)
+		}
+	})
+
+	t.Run("Assemble should know constants, aliases, calc, macros, unpack, next and org", func(t *testing.T) {
+		rom, err := Assemble(`
+			:const SPEED 3
+			:alias x v5
+			:calc DOUBLE { SPEED * 2 + 1 }
+			:macro move reg amount { reg += amount }
+			: main
+				x := SPEED
+				move x DOUBLE
+				:unpack 0xA target
+				:next patched
+				v0 := 0
+			: target
+				:org 0x20E
+				0xFF`)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint8{0x12, 0x02, 0x65, 0x03, 0x75, 0x07, 0x60, 0xA2, 0x61, 0x0C, 0x60, 0x00, 0x00, 0x00, 0xFF}, rom)
+	})
+
+	t.Run("Assemble should report the line of the errors", func(t *testing.T) {
+		cases := map[string]string{
+			": main\n  jump nowhere":  `line 2: undefined label "nowhere"`,
+			": main\n  v0 := 256":     "line 2: 256 doesn't fit in a byte",
+			": main\n\n  hires":       "line 3: hires is a SCHIP or XO-CHIP statement, only chip-8 is supported",
+			": main\n  save v0 - v3":  "line 2: save with a range of registers is an XO-CHIP statement, only chip-8 is supported",
+			": main\n  loop\n  clear": "line 2: loop isn't closed",
+			": main\n  else":          "line 2: else without the start of its block",
+			": main\n  sprite v0 v1":  "line 2: the program ends in the middle of a statement",
+			"clear":                   "line 1: the program has no main label",
+		}
+		for source, want := range cases {
+			_, err := Assemble(source)
+			var e *Error
+			if assert.True(t, errors.As(err, &e), source) {
+				assert.Equal(t, want, e.Error())
+			}
+		}
+	})
+}
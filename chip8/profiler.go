@@ -0,0 +1,236 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// OperationProfile: how many times the instructions of an operation ran
+type OperationProfile struct {
+	Op   Operation
+	Hits int64
+}
+
+// SubroutineProfile: where a subroutine spent the instructions. The code outside of every
+// subroutine is profiled as one too, with the address the program started at as its entry.
+type SubroutineProfile struct {
+	Entry uint16
+	// Calls is how many times it was called
+	Calls int64
+	// Self is how many instructions ran in it, Total adds the ones run by the subroutines it called
+	Self, Total int64
+}
+
+// profilerFrame: a subroutine being run, with the address it returns to
+type profilerFrame struct {
+	entry, ret uint16
+}
+
+// Profiler counts the instructions a running Chip8 executes by address, by operation and
+// by subroutine. The subroutines are followed through the stack of the state: a new level
+// is a call to the address fetched next, and a missing one, or one that returns somewhere
+// else, is a return.
+type Profiler struct {
+	c8           *Chip8
+	hits         [MemorySize]int64
+	operations   [operationCount]int64
+	instructions int64
+	frames       []profilerFrame
+	subroutines  map[uint16]*SubroutineProfile
+	// stack is the folded stack of the frames, stacks counts the instructions run in each
+	stack  string
+	stacks map[string]int64
+}
+
+// Profile: starts profiling every instruction the Chip8 runs
+func Profile(c8 *Chip8) *Profiler {
+	p := &Profiler{c8: c8, subroutines: map[uint16]*SubroutineProfile{}, stacks: map[string]int64{}}
+	previous := c8.Hooks.InstructionFetched
+	c8.Hooks.InstructionFetched = func(pc uint16, in Instruction) {
+		if previous != nil {
+			previous(pc, in)
+		}
+		p.record(pc, in)
+	}
+	return p
+}
+
+func (p *Profiler) record(pc uint16, in Instruction) {
+	p.follow(pc)
+	p.hits[pc&AddressMask]++
+	p.operations[in.Op]++
+	p.instructions++
+	p.stacks[p.stack]++
+
+	top := p.frames[len(p.frames)-1].entry
+	p.subroutines[top].Self++
+	for i, f := range p.frames {
+		if !p.onStackBelow(f.entry, i) {
+			p.subroutines[f.entry].Total++
+		}
+	}
+}
+
+// follow: brings the frames in line with the stack of the state before the instruction at pc runs
+func (p *Profiler) follow(pc uint16) {
+	s := &p.c8.CurrState
+	depth := int(s.SP)
+	if depth > StackSize {
+		depth = StackSize
+	}
+	if len(p.frames) == 0 {
+		p.push(pc, 0)
+	}
+	// the frame at index k returns to the address on level k-1 of the stack
+	for len(p.frames) > 1 {
+		level := len(p.frames) - 2
+		if level < depth && p.frames[len(p.frames)-1].ret == s.Stack[level] {
+			break
+		}
+		p.pop()
+	}
+	for len(p.frames)-1 < depth {
+		p.push(pc, s.Stack[len(p.frames)-1])
+		p.subroutines[pc].Calls++
+	}
+}
+
+func (p *Profiler) push(entry, ret uint16) {
+	p.frames = append(p.frames, profilerFrame{entry, ret})
+	if p.subroutines[entry] == nil {
+		p.subroutines[entry] = &SubroutineProfile{Entry: entry}
+	}
+	p.foldStack()
+}
+
+func (p *Profiler) pop() {
+	p.frames = p.frames[:len(p.frames)-1]
+	p.foldStack()
+}
+
+// onStackBelow: true if the subroutine is on one of the frames below the level, so recursive
+// calls count once towards the total
+func (p *Profiler) onStackBelow(entry uint16, level int) bool {
+	for _, f := range p.frames[:level] {
+		if f.entry == entry {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Profiler) foldStack() {
+	names := make([]string, len(p.frames))
+	for i, f := range p.frames {
+		names[i] = p.subroutineName(f.entry)
+	}
+	p.stack = strings.Join(names, ";")
+}
+
+func (p *Profiler) subroutineName(entry uint16) string {
+	if len(p.frames) > 0 && entry == p.frames[0].entry {
+		return "main"
+	}
+	return fmt.Sprintf("sub_%03X", entry)
+}
+
+// Instructions: how many instructions were profiled
+func (p *Profiler) Instructions() int64 {
+	return p.instructions
+}
+
+// Hits: how many times the instruction at the address ran
+func (p *Profiler) Hits(addr uint16) int64 {
+	return p.hits[addr&AddressMask]
+}
+
+// Operations: the operations that ran, the most run first
+func (p *Profiler) Operations() []OperationProfile {
+	var profiles []OperationProfile
+	for op, hits := range p.operations {
+		if hits > 0 {
+			profiles = append(profiles, OperationProfile{Operation(op), hits})
+		}
+	}
+	sort.SliceStable(profiles, func(i, j int) bool {
+		return profiles[i].Hits > profiles[j].Hits
+	})
+	return profiles
+}
+
+// Subroutines: the subroutines that ran, the main code first and then by the instructions they
+// and their calls ran, the most first
+func (p *Profiler) Subroutines() []SubroutineProfile {
+	if len(p.frames) == 0 {
+		return nil
+	}
+	var profiles []SubroutineProfile
+	for _, s := range p.subroutines {
+		profiles = append(profiles, *s)
+	}
+	main := p.frames[0].entry
+	sort.Slice(profiles, func(i, j int) bool {
+		a, b := profiles[i], profiles[j]
+		if (a.Entry == main) != (b.Entry == main) {
+			return a.Entry == main
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Entry < b.Entry
+	})
+	return profiles
+}
+
+// WriteFolded: writes the instructions run by each call stack in the folded format of
+// flamegraph.pl and speedscope, one "main;sub_2A0;sub_2F4 1234" line per stack
+func (p *Profiler) WriteFolded(w io.Writer) error {
+	stacks := make([]string, 0, len(p.stacks))
+	for stack := range p.stacks {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+	bw := bufio.NewWriter(w)
+	for _, stack := range stacks {
+		fmt.Fprintf(bw, "%s %d\n", stack, p.stacks[stack])
+	}
+	return bw.Flush()
+}
+
+// WriteCoverage: writes the disassembly of the memory from start to end with how many times
+// each instruction ran, or a - for the ones that never did. Instructions run at odd addresses
+// are listed too, with the byte before them on a line of its own.
+func (p *Profiler) WriteCoverage(w io.Writer, start, end uint16) error {
+	memory := &p.c8.CurrState.Memory
+	if int(end) > MemorySize {
+		end = MemorySize
+	}
+	executed := 0
+	for addr := start; addr < end; addr++ {
+		if p.hits[addr] > 0 {
+			executed++
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# 0x%03X-0x%03X: %d instructions run from %d addresses\n", start, end-1, p.instructions, executed)
+	fmt.Fprintln(bw, "#     hits  addr  opcode  instruction")
+	for addr := start; addr < end; {
+		if addr+1 == end || p.hits[addr] == 0 && p.hits[addr+1] > 0 {
+			fmt.Fprintf(bw, "%10s  %03X   %02X\n", "-", addr, memory[addr])
+			addr++
+			continue
+		}
+		opcode := uint16(memory[addr])<<ByteSize | uint16(memory[addr+1])
+		hits := "-"
+		if p.hits[addr] > 0 {
+			hits = fmt.Sprint(p.hits[addr])
+		}
+		fmt.Fprintf(bw, "%10s  %03X   %04X    %s\n", hits, addr, opcode, Disassemble(opcode))
+		addr += OpcodeSize
+	}
+	return bw.Flush()
+}
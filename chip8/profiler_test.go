@@ -0,0 +1,95 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiler(t *testing.T) {
+	c := New()
+	c.LoadGame([]uint8{
+		0x22, 0x08, // 200: CALL 0x208
+		0x22, 0x0C, // 202: CALL 0x20C
+		0x12, 0x04, // 204: JP 0x204
+		0x00, 0x00, // 206
+		0x22, 0x0C, // 208: CALL 0x20C
+		0x00, 0xEE, // 20A: RET
+		0x60, 0x01, // 20C: LD V0, 0x01
+		0x00, 0xEE, // 20E: RET
+	})
+	p := Profile(c)
+	for i := 0; i < 10; i++ {
+		c.Tick(0)
+	}
+
+	t.Run("Profile should count the hits of every address", func(t *testing.T) {
+		assert.Equal(t, int64(10), p.Instructions())
+		assert.Equal(t, int64(1), p.Hits(0x200))
+		assert.Equal(t, int64(2), p.Hits(0x204))
+		assert.Equal(t, int64(0), p.Hits(0x206))
+		assert.Equal(t, int64(2), p.Hits(0x20C))
+	})
+
+	t.Run("Profile should count the hits of every operation", func(t *testing.T) {
+		assert.Equal(t, []OperationProfile{{OpRET, 3}, {OpCALL, 3}, {OpJP, 2}, {OpLDByte, 2}}, p.Operations())
+		assert.Equal(t, "LD Vx, byte", OpLDByte.String())
+	})
+
+	t.Run("Profile should follow the calls and returns on the stack", func(t *testing.T) {
+		assert.Equal(t, []SubroutineProfile{
+			{Entry: 0x200, Calls: 0, Self: 4, Total: 10},
+			{Entry: 0x208, Calls: 1, Self: 2, Total: 4},
+			{Entry: 0x20C, Calls: 2, Self: 4, Total: 4},
+		}, p.Subroutines())
+	})
+
+	t.Run("WriteFolded should write the instructions of each call stack", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, p.WriteFolded(&b))
+		assert.Equal(t, "main 4\nmain;sub_208 2\nmain;sub_208;sub_20C 2\nmain;sub_20C 2\n", b.String())
+	})
+
+	t.Run("WriteCoverage should annotate the disassembly with the hits", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, p.WriteCoverage(&b, 0x200, 0x210))
+		assert.Equal(t, ""+
+			"# 0x200-0x20F: 10 instructions run from 7 addresses\n"+
+			"#     hits  addr  opcode  instruction\n"+
+			"         1  200   2208    CALL 0x208\n"+
+			"         1  202   220C    CALL 0x20C\n"+
+			"         2  204   1204    JP 0x204\n"+
+			"         -  206   0000    SYS 0x000\n"+
+			"         1  208   220C    CALL 0x20C\n"+
+			"         1  20A   00EE    RET\n"+
+			"         2  20C   6001    LD V0, 0x01\n"+
+			"         2  20E   00EE    RET\n", b.String())
+	})
+
+	t.Run("WriteCoverage should list the byte before an instruction at an odd address", func(t *testing.T) {
+		c := New()
+		c.LoadGame([]uint8{0x12, 0x03, 0x00, 0x12, 0x03})
+		p := Profile(c)
+		c.Tick(0)
+		c.Tick(0)
+		var b bytes.Buffer
+		assert.NoError(t, p.WriteCoverage(&b, 0x200, 0x205))
+		assert.Contains(t, b.String(), "         -  202   00\n         1  203   1203    JP 0x203\n")
+	})
+
+	t.Run("Profile should take a different return address on the stack as a new call", func(t *testing.T) {
+		c := New()
+		c.LoadGame([]uint8{0x22, 0x04, 0x12, 0x02, 0x00, 0xEE})
+		p := Profile(c)
+		c.Tick(0)
+		c.Tick(0)
+		// a debugger puts the machine back in the subroutine, called from somewhere else
+		c.CurrState.PC, c.CurrState.SP, c.CurrState.Stack[0] = 0x204, 1, 0x300
+		c.Tick(0)
+		assert.Equal(t, []SubroutineProfile{
+			{Entry: 0x200, Self: 1, Total: 3},
+			{Entry: 0x204, Calls: 2, Self: 2, Total: 2},
+		}, p.Subroutines())
+	})
+}
@@ -0,0 +1,36 @@
+package chip8
+
+// Quirks are the behaviours that changed between chip-8 interpreters over the years.
+// The zero value is how the original COSMAC VIP interpreter behaves.
+type Quirks struct {
+	// Shift: 8xy6 and 8xyE shift Vx in place, instead of loading Vy shifted into Vx
+	Shift bool
+	// MemoryIncrementByX: Fx55 and Fx65 increment I by x, instead of x + 1
+	MemoryIncrementByX bool
+	// MemoryLeaveIUnchanged: Fx55 and Fx65 don't change I
+	MemoryLeaveIUnchanged bool
+	// Jump: Bnnn jumps to nnn + Vx (x being the highest nibble of nnn), instead of nnn + V0
+	Jump bool
+	// Logic: 8xy1, 8xy2 and 8xy3 reset VF to 0
+	Logic bool
+	// Wrap: sprites drawn across the edges of the screen wrap around to the other side,
+	// instead of being clipped. The starting coordinate always wraps.
+	Wrap bool
+	// CountClippedRows: like SCHIP, Dxyn sets VF to the number of rows that collided plus
+	// the rows clipped by the bottom of the screen, instead of 1 on any collision. Only
+	// used when sprites are clipped.
+	CountClippedRows bool
+	// DisplayWait: Dxyn waits for the vertical blank before drawing, so at most one sprite
+	// is drawn on each 60Hz frame. It's always on with COSMAC VIP timing.
+	DisplayWait bool
+}
+
+// DefaultQuirks: the quirks used when none are selected, which are close to SCHIP
+var DefaultQuirks = Quirks{
+	Shift:                 true,
+	MemoryLeaveIUnchanged: true,
+	Wrap:                  true,
+}
+
+// DefaultTickRate: how many instructions run on every 60Hz frame when no tick rate is selected
+const DefaultTickRate = 8
@@ -0,0 +1,180 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuirks(t *testing.T) {
+	t.Run("8xy6 and 8xyE should shift Vy into Vx without the shift quirk", func(t *testing.T) {
+		c := New()
+		c.Quirks = Quirks{}
+		c.CurrState.V[0x0] = 0xFF
+		c.CurrState.V[0x1] = 0b00000100
+
+		assert.Equal(t, uint8(0b00000010), c.ExecuteOpcode(0x8016).V[0x0])
+		assert.Equal(t, uint8(0b00001000), c.ExecuteOpcode(0x801E).V[0x0])
+
+		c.Quirks.Shift = true
+		assert.Equal(t, uint8(0x7F), c.ExecuteOpcode(0x8016).V[0x0], "Vx should be shifted in place")
+	})
+
+	t.Run("Fx55 and Fx65 should move I according to the memory quirks", func(t *testing.T) {
+		c := New()
+		c.CurrState.I = 0x300
+
+		c.Quirks = Quirks{}
+		assert.Equal(t, uint16(0x303), c.ExecuteOpcode(0xF255).I, "I should be incremented by x + 1")
+		assert.Equal(t, uint16(0x303), c.ExecuteOpcode(0xF265).I, "I should be incremented by x + 1")
+
+		c.Quirks = Quirks{MemoryIncrementByX: true}
+		assert.Equal(t, uint16(0x302), c.ExecuteOpcode(0xF255).I, "I should be incremented by x")
+
+		c.Quirks = Quirks{MemoryLeaveIUnchanged: true}
+		assert.Equal(t, uint16(0x300), c.ExecuteOpcode(0xF265).I, "I should be left unchanged")
+	})
+
+	t.Run("Bnnn should jump to nnn + Vx with the jump quirk", func(t *testing.T) {
+		c := New()
+		c.CurrState.V[0x0] = 0x10
+		c.CurrState.V[0x3] = 0x20
+
+		c.Quirks = Quirks{}
+		assert.Equal(t, uint16(0x350), c.ExecuteOpcode(0xB340).PC)
+
+		c.Quirks = Quirks{Jump: true}
+		assert.Equal(t, uint16(0x360), c.ExecuteOpcode(0xB340).PC)
+	})
+
+	t.Run("8xy1, 8xy2 and 8xy3 should reset VF with the logic quirk", func(t *testing.T) {
+		c := New()
+		c.CurrState.V[0xF] = 0x01
+
+		c.Quirks = Quirks{}
+		for _, opcode := range []uint16{0x8011, 0x8012, 0x8013} {
+			assert.Equal(t, uint8(0x01), c.ExecuteOpcode(opcode).V[0xF], "%04X", opcode)
+		}
+
+		c.Quirks = Quirks{Logic: true}
+		for _, opcode := range []uint16{0x8011, 0x8012, 0x8013} {
+			assert.Equal(t, uint8(0x00), c.ExecuteOpcode(opcode).V[0xF], "%04X", opcode)
+		}
+	})
+
+	t.Run("Timers should count down once every TickRate instructions", func(t *testing.T) {
+		c := New()
+		c.TickRate = 4
+		assert.NoError(t, c.LoadGame([]uint8{0x12, 0x00}))
+		c.CurrState.DelayTimer = 10
+
+		for i := 0; i < 8; i++ {
+			c.Tick(0)
+		}
+		assert.Equal(t, uint8(8), c.CurrState.DelayTimer)
+	})
+
+	t.Run("Dxyn should wait for the next frame with the display wait quirk", func(t *testing.T) {
+		c := New()
+		c.Quirks.DisplayWait = true
+		c.TickRate = 4
+		assert.NoError(t, c.LoadGame([]uint8{0xD0, 0x01, 0xD0, 0x01, 0x12, 0x04}))
+		c.CurrState.DelayTimer = 10
+		ctl := NewController(c)
+
+		ctl.StepFrame()
+		assert.Equal(t, uint16(ProgramStartAddress), c.CurrState.PC, "Dxyn should wait for the first frame to end")
+		assert.Equal(t, uint8(9), c.CurrState.DelayTimer, "Timers should keep counting down while waiting")
+		assert.Equal(t, int64(4), c.TickCount)
+		assert.Equal(t, int64(0), c.Executed, "Ticks spent waiting shouldn't count as instructions run")
+
+		ctl.StepFrame()
+		assert.Equal(t, uint16(ProgramStartAddress+2), c.CurrState.PC, "Only one Dxyn should run on each frame")
+		ctl.StepFrame()
+		assert.Equal(t, uint16(ProgramStartAddress+4), c.CurrState.PC)
+	})
+
+	t.Run("Dxyn should wait for the next frame even when it isn't the first instruction of its frame", func(t *testing.T) {
+		c := New()
+		c.Quirks.DisplayWait = true
+		c.TickRate = 4
+		assert.NoError(t, c.LoadGame([]uint8{0x60, 0x01, 0x70, 0x01, 0x70, 0x01, 0x70, 0x01, 0x70, 0x01, 0xD0, 0x01, 0x12, 0x0C}))
+		ctl := NewController(c)
+
+		ctl.StepFrame()
+		assert.Equal(t, uint16(ProgramStartAddress+8), c.CurrState.PC)
+		ctl.StepFrame()
+		assert.Equal(t, uint16(ProgramStartAddress+10), c.CurrState.PC, "Dxyn should wait for the end of the frame it was reached in")
+		ctl.StepFrame()
+		assert.Equal(t, uint16(ProgramStartAddress+12), c.CurrState.PC)
+	})
+
+	t.Run("Dxyn should draw right away without the display wait quirk", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadGame([]uint8{0xD0, 0x01, 0xD0, 0x01}))
+		c.Tick(0)
+		c.Tick(0)
+		assert.Equal(t, uint16(ProgramStartAddress+4), c.CurrState.PC)
+	})
+
+	t.Run("Dxyn should wrap sprites around the edges with the wrap quirk", func(t *testing.T) {
+		c := New()
+		c.Quirks = Quirks{Wrap: true}
+		c.CurrState.I = 0x300
+		c.CurrState.Memory[0x300] = 0xFF
+		c.CurrState.Memory[0x301] = 0xFF
+		c.CurrState.V[0x0] = 60
+		c.CurrState.V[0x1] = 31
+
+		newState := c.ExecuteOpcode(0xD012)
+		assert.True(t, newState.GetPixel(63, 31))
+		assert.True(t, newState.GetPixel(0, 31), "Columns past the right edge should wrap")
+		assert.True(t, newState.GetPixel(3, 0), "Rows past the bottom edge should wrap")
+	})
+
+	t.Run("Dxyn should clip sprites at the edges without the wrap quirk", func(t *testing.T) {
+		c := New()
+		c.Quirks = Quirks{}
+		c.CurrState.I = 0x300
+		c.CurrState.Memory[0x300] = 0xFF
+		c.CurrState.Memory[0x301] = 0xFF
+		c.CurrState.V[0x0] = 60
+		c.CurrState.V[0x1] = 31
+
+		newState := c.ExecuteOpcode(0xD012)
+		assert.True(t, newState.GetPixel(63, 31))
+		assert.False(t, newState.GetPixel(0, 31), "Columns past the right edge should be clipped")
+		assert.Equal(t, uint64(0), newState.Graphics[0], "Rows past the bottom edge should be clipped")
+	})
+
+	t.Run("Dxyn should always wrap the starting coordinate", func(t *testing.T) {
+		c := New()
+		c.Quirks = Quirks{}
+		c.CurrState.I = 0x300
+		c.CurrState.Memory[0x300] = 0x80
+		c.CurrState.V[0x0] = 64 + 5
+		c.CurrState.V[0x1] = 32 + 2
+
+		newState := c.ExecuteOpcode(0xD011)
+		assert.True(t, newState.GetPixel(5, 2))
+	})
+
+	t.Run("Dxyn should count collided and clipped rows into VF like SCHIP", func(t *testing.T) {
+		c := New()
+		c.Quirks = Quirks{CountClippedRows: true}
+		c.CurrState.I = 0x300
+		for i := 0; i < 4; i++ {
+			c.CurrState.Memory[0x300+i] = 0x80
+		}
+		c.CurrState.V[0x0] = 0
+		c.CurrState.V[0x1] = 30
+		c.CurrState.SetPixel(0, 30)
+
+		newState := c.ExecuteOpcode(0xD014)
+		assert.Equal(t, uint8(3), newState.V[0xF], "1 row collided and 2 rows were clipped")
+
+		c.Quirks.CountClippedRows = false
+		newState = c.ExecuteOpcode(0xD014)
+		assert.Equal(t, uint8(1), newState.V[0xF], "VF should be 1 on any collision")
+	})
+}
@@ -0,0 +1,272 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/franciscocid/chip-8/chip8/octo"
+)
+
+type ROMFormat string
+
+const (
+	FormatCHIP8         ROMFormat = "ch8"
+	FormatSuperChip     ROMFormat = "sc8"
+	FormatXOChip        ROMFormat = "xo8"
+	FormatOctoCartridge ROMFormat = "gif"
+)
+
+// Platform is the machine the ROM was written for, it decides where programs
+// are loaded and how much memory they can use
+type Platform struct {
+	Name        string
+	LoadAddress uint16
+	MemorySize  int
+}
+
+var (
+	PlatformCHIP8     = Platform{Name: "chip8", LoadAddress: ProgramStartAddress, MemorySize: MemorySize}
+	PlatformETI660    = Platform{Name: "eti660", LoadAddress: 0x600, MemorySize: MemorySize}
+	PlatformSuperChip = Platform{Name: "schip", LoadAddress: ProgramStartAddress, MemorySize: MemorySize}
+	// PlatformXOChip: XO-CHIP programs can address 64KB, of which only the first 4KB are emulated
+	PlatformXOChip = Platform{Name: "xochip", LoadAddress: ProgramStartAddress, MemorySize: 0x10000}
+
+	// Platforms: the supported platforms by name
+	Platforms = map[string]Platform{
+		PlatformCHIP8.Name:     PlatformCHIP8,
+		PlatformETI660.Name:    PlatformETI660,
+		PlatformSuperChip.Name: PlatformSuperChip,
+		PlatformXOChip.Name:    PlatformXOChip,
+	}
+)
+
+var (
+	ErrEmptyROM         = errors.New("ROM is empty")
+	ErrROMTooLarge      = errors.New("ROM is too large")
+	ErrInvalidCartridge = errors.New("invalid Octo cartridge")
+)
+
+type ROM struct {
+	Name   string
+	Format ROMFormat
+	Data   []uint8
+	// Info is what the ROM database knows about the ROM, or the options of an Octo
+	// cartridge the database doesn't know. nil if there's neither.
+	Info *ROMInfo
+}
+
+// formatPlatforms: the platform the programs in a format are written for, raw chip-8
+// binaries and cartridges run on the platform selected
+var formatPlatforms = map[ROMFormat]Platform{
+	FormatSuperChip: PlatformSuperChip,
+	FormatXOChip:    PlatformXOChip,
+}
+
+// PlatformNames: names of the supported platforms, sorted
+func PlatformNames() []string {
+	names := make([]string, 0, len(Platforms))
+	for name := range Platforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Capacity: how many bytes a program can have on the platform
+func (p Platform) Capacity() int {
+	size := p.MemorySize
+	if size > MemorySize {
+		size = MemorySize
+	}
+	return size - int(p.LoadAddress)
+}
+
+// ReadROM: reads a ROM, detecting its format by the content and the name extension.
+// Octo cartridges are detected by the GIF header, .sc8 and .xo8 by the extension and
+// anything else is read as a raw chip-8 binary. Known ROMs get their Info from the ROM database,
+// other cartridges from their options.
+func ReadROM(name string, r io.Reader) (*ROM, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading ROM %s: %w", name, err)
+	}
+
+	rom := &ROM{Name: name, Format: FormatCHIP8, Data: data}
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		rom.Format = FormatOctoCartridge
+		if rom.Data, rom.Info, err = decodeOctoCartridge(data); err != nil {
+			return nil, fmt.Errorf("reading ROM %s: %w", name, err)
+		}
+	case strings.EqualFold(filepath.Ext(name), ".sc8"):
+		rom.Format = FormatSuperChip
+	case strings.EqualFold(filepath.Ext(name), ".xo8"):
+		rom.Format = FormatXOChip
+	}
+
+	if len(rom.Data) == 0 {
+		return nil, fmt.Errorf("reading ROM %s: %w", name, ErrEmptyROM)
+	}
+
+	db, err := DefaultDatabase()
+	if err != nil {
+		return nil, err
+	}
+	if info, ok := db.Lookup(rom.Data); ok {
+		rom.Info = info
+	}
+	return rom, nil
+}
+
+// LoadROMFile: reads the ROM at the path, see ReadROM
+func LoadROMFile(path string) (*ROM, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadROM(filepath.Base(path), file)
+}
+
+// LoadROM: loads the ROM into memory, see LoadGame. When the ROM has Info its
+// platform, quirks, tick rate and font are selected first, otherwise .sc8 and .xo8
+// ROMs select the platform of their format. A font that would overlap the program
+// moves to FontsStartAddress. Nothing changes when the ROM can't be loaded.
+func (c *Chip8) LoadROM(rom *ROM) error {
+	platform, quirks, tickRate, font, fontAddress := c.Platform, c.Quirks, c.TickRate, c.Font, c.FontAddress
+	if formatPlatform, ok := formatPlatforms[rom.Format]; ok {
+		platform = formatPlatform
+	}
+	if info := rom.Info; info != nil {
+		platform = info.platform(platform)
+		quirks = info.Quirks
+		tickRate = info.TickRate
+		if infoFont, ok := FontSets[info.FontStyle]; ok {
+			font = infoFont
+		}
+	}
+	if platform.MemorySize == 0 {
+		platform = PlatformCHIP8
+	}
+	if len(font.Small) == 0 {
+		font, fontAddress = DefaultFont, FontsStartAddress
+	}
+
+	if err := checkGame(rom.Data, platform); err != nil {
+		return fmt.Errorf("loading ROM %s: %w", rom.Name, err)
+	}
+	if checkFont(font, fontAddress, platform) != nil {
+		fontAddress = FontsStartAddress
+		if err := checkFont(font, fontAddress, platform); err != nil {
+			return fmt.Errorf("loading ROM %s: %w", rom.Name, err)
+		}
+	}
+
+	c.Platform, c.Quirks, c.TickRate, c.Font, c.FontAddress = platform, quirks, tickRate, font, fontAddress
+	c.ROM = rom
+	if err := c.LoadGame(rom.Data); err != nil {
+		return fmt.Errorf("loading ROM %s: %w", rom.Name, err)
+	}
+	return nil
+}
+
+// cartridgePayload: the JSON payload of the cartridges saved by Octo
+type cartridgePayload struct {
+	Program string `json:"program"`
+	Options struct {
+		TickRate        int    `json:"tickrate"`
+		FillColor       string `json:"fillColor"`
+		BackgroundColor string `json:"backgroundColor"`
+		ShiftQuirks     bool   `json:"shiftQuirks"`
+		LoadStoreQuirks bool   `json:"loadStoreQuirks"`
+		JumpQuirks      bool   `json:"jumpQuirks"`
+		LogicQuirks     bool   `json:"logicQuirks"`
+		ClipQuirks      bool   `json:"clipQuirks"`
+		VBlankQuirks    bool   `json:"vBlankQuirks"`
+		FontStyle       string `json:"fontStyle"`
+	} `json:"options"`
+}
+
+// decodeOctoCartridge: Octo cartridges are GIFs where every byte of the payload is
+// spread over the 2 least significant bits of the color index of 4 pixels, starting
+// with a 32 bits big endian length. The payload is JSON with the Octo source, which is
+// assembled, and the options, which become the Info; anything that isn't JSON is taken
+// as the program binary.
+func decodeOctoCartridge(data []uint8) ([]uint8, *ROMInfo, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidCartridge, err)
+	}
+
+	var payload []uint8
+	var current uint8
+	var bits int
+	for _, frame := range g.Image {
+		payload = appendCartridgeBytes(payload, frame, &current, &bits)
+	}
+
+	if len(payload) < 4 {
+		return nil, nil, fmt.Errorf("%w: missing payload length", ErrInvalidCartridge)
+	}
+	size := int(payload[0])<<24 | int(payload[1])<<16 | int(payload[2])<<8 | int(payload[3])
+	if size > len(payload)-4 {
+		return nil, nil, fmt.Errorf("%w: payload has %d bytes but %d were expected", ErrInvalidCartridge, len(payload)-4, size)
+	}
+	payload = payload[4 : 4+size]
+
+	var cartridge cartridgePayload
+	if json.Unmarshal(payload, &cartridge) != nil {
+		return payload, nil, nil
+	}
+	program, err := octo.Assemble(cartridge.Program)
+	if err != nil {
+		return nil, nil, fmt.Errorf("assembling the Octo source: %w", err)
+	}
+
+	options := cartridge.Options
+	info := &ROMInfo{
+		Quirks: Quirks{
+			Shift:                 options.ShiftQuirks,
+			MemoryLeaveIUnchanged: options.LoadStoreQuirks,
+			Jump:                  options.JumpQuirks,
+			Logic:                 options.LogicQuirks,
+			Wrap:                  !options.ClipQuirks,
+			DisplayWait:           options.VBlankQuirks,
+		},
+		TickRate:  options.TickRate,
+		FontStyle: options.FontStyle,
+		// Octo assembles every program for 0x200, whatever the platform selected
+		StartAddress: octo.StartAddress,
+	}
+	if info.TickRate <= 0 {
+		info.TickRate = DefaultTickRate
+	}
+	background, okBackground := parseHexColor(options.BackgroundColor)
+	fill, okFill := parseHexColor(options.FillColor)
+	if okBackground && okFill {
+		info.Palette = []color.RGBA{background, fill}
+	}
+	return program, info, nil
+}
+
+func appendCartridgeBytes(payload []uint8, frame *image.Paletted, current *uint8, bits *int) []uint8 {
+	for _, index := range frame.Pix {
+		*current = *current<<2 | index&0x3
+		*bits += 2
+		if *bits == ByteSize {
+			payload = append(payload, *current)
+			*current, *bits = 0, 0
+		}
+	}
+	return payload
+}
@@ -0,0 +1,172 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/franciscocid/chip-8/chip8/octo"
+	"github.com/stretchr/testify/assert"
+)
+
+// octoCartridge: builds a GIF with the payload encoded the way Octo does
+func octoCartridge(payload []uint8) []uint8 {
+	data := append([]uint8{uint8(len(payload) >> 24), uint8(len(payload) >> 16), uint8(len(payload) >> 8), uint8(len(payload))}, payload...)
+	palette := color.Palette{color.Black, color.White, color.Gray{0x40}, color.Gray{0x80}}
+	frame := image.NewPaletted(image.Rect(0, 0, len(data)*4, 1), palette)
+	for i, value := range data {
+		for bit := 0; bit < 4; bit++ {
+			frame.Pix[i*4+bit] = value >> (6 - bit*2) & 0x3
+		}
+	}
+	var buf bytes.Buffer
+	gif.EncodeAll(&buf, &gif.GIF{Image: []*image.Paletted{frame}, Delay: []int{0}})
+	return buf.Bytes()
+}
+
+func TestROM(t *testing.T) {
+	t.Run("ReadROM should detect the format from the extension", func(t *testing.T) {
+		cases := map[string]ROMFormat{
+			"pong.ch8":   FormatCHIP8,
+			"pong":       FormatCHIP8,
+			"ant.sc8":    FormatSuperChip,
+			"ANT.SC8":    FormatSuperChip,
+			"flight.xo8": FormatXOChip,
+		}
+		for name, format := range cases {
+			rom, err := ReadROM(name, bytes.NewReader([]uint8{0x00, 0xE0}))
+			assert.NoError(t, err)
+			assert.Equal(t, format, rom.Format, name)
+			assert.Nil(t, rom.Info, name)
+		}
+	})
+
+	t.Run("ReadROM should refuse empty ROMs", func(t *testing.T) {
+		_, err := ReadROM("empty.ch8", bytes.NewReader(nil))
+		assert.True(t, errors.Is(err, ErrEmptyROM))
+	})
+
+	t.Run("ReadROM should decode Octo cartridges", func(t *testing.T) {
+		rom, err := ReadROM("cart.gif", bytes.NewReader(octoCartridge([]uint8{0x00, 0xE0, 0x12, 0x00})))
+		assert.NoError(t, err)
+		assert.Equal(t, FormatOctoCartridge, rom.Format)
+		assert.Equal(t, []uint8{0x00, 0xE0, 0x12, 0x00}, rom.Data)
+
+		_, err = ReadROM("cart.gif", bytes.NewReader([]uint8("GIF89a broken")))
+		assert.True(t, errors.Is(err, ErrInvalidCartridge))
+	})
+
+	t.Run("ReadROM should assemble the source of Octo cartridges and take their options", func(t *testing.T) {
+		payload := `{"program": ": main\n  clear\n  jump main", "options": {"tickrate": 20,
+			"fillColor": "#FFCC00", "backgroundColor": "#996600", "shiftQuirks": true,
+			"loadStoreQuirks": true, "clipQuirks": true, "vBlankQuirks": true, "fontStyle": "vip"}}`
+		rom, err := ReadROM("cart.gif", bytes.NewReader(octoCartridge([]uint8(payload))))
+		assert.NoError(t, err)
+		assert.Equal(t, FormatOctoCartridge, rom.Format)
+		assert.Equal(t, []uint8{0x00, 0xE0, 0x12, 0x00}, rom.Data)
+		if assert.NotNil(t, rom.Info, "The options should become the info of a ROM the database doesn't know") {
+			assert.Equal(t, Quirks{Shift: true, MemoryLeaveIUnchanged: true, DisplayWait: true}, rom.Info.Quirks)
+			assert.Equal(t, 20, rom.Info.TickRate)
+			assert.Equal(t, "vip", rom.Info.FontStyle)
+			assert.Equal(t, []color.RGBA{{R: 0x99, G: 0x66, A: 0xFF}, {R: 0xFF, G: 0xCC, A: 0xFF}}, rom.Info.Palette)
+		}
+
+		var octoErr *octo.Error
+		_, err = ReadROM("cart.gif", bytes.NewReader(octoCartridge([]uint8(`{"program": ": main\n  hires"}`))))
+		assert.True(t, errors.As(err, &octoErr), "Should tell what the assembler didn't like")
+		assert.Contains(t, err.Error(), "cart.gif")
+	})
+
+	t.Run("LoadROM should select the platform of the format when the ROM has no info", func(t *testing.T) {
+		cases := map[ROMFormat]Platform{
+			FormatCHIP8:     PlatformETI660,
+			FormatSuperChip: PlatformSuperChip,
+			FormatXOChip:    PlatformXOChip,
+		}
+		for format, platform := range cases {
+			c := New()
+			c.Platform = PlatformETI660
+			assert.NoError(t, c.LoadROM(&ROM{Name: "rom", Format: format, Data: []uint8{0x00, 0xE0}}))
+			assert.Equal(t, platform, c.Platform, "Raw binaries should keep the platform selected, %s", format)
+		}
+	})
+
+	t.Run("LoadGame should refuse ROMs that don't fit in memory", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.LoadGame(make([]uint8, MemorySize-0x200)), "Should fit exactly")
+		assert.True(t, errors.Is(c.LoadGame(make([]uint8, MemorySize-0x200+1)), ErrROMTooLarge))
+		assert.True(t, errors.Is(c.LoadGame(nil), ErrEmptyROM))
+
+		c.Platform = PlatformETI660
+		assert.True(t, errors.Is(c.LoadGame(make([]uint8, MemorySize-0x200)), ErrROMTooLarge), "ETI-660 programs have less memory")
+	})
+
+	t.Run("LoadGame should load at the platform address along with the fonts", func(t *testing.T) {
+		c := New()
+		c.Platform = PlatformETI660
+		assert.NoError(t, c.LoadGame([]uint8{0x00, 0xE0}))
+		assert.Equal(t, uint16(0x600), c.CurrState.PC)
+		assert.Equal(t, uint16(0x00E0), c.CurrState.Opcode())
+		assert.Equal(t, DefaultFont.Small, c.CurrState.Memory[FontsStartAddress:FontsStartAddress+SmallFontSize])
+	})
+
+	t.Run("LoadROM should tell which ROM failed", func(t *testing.T) {
+		c := New()
+		err := c.LoadROM(&ROM{Name: "huge.ch8", Data: make([]uint8, MemorySize)})
+		assert.True(t, errors.Is(err, ErrROMTooLarge))
+		assert.Contains(t, err.Error(), "huge.ch8")
+	})
+
+	t.Run("LoadROM should leave the machine and its ROM alone when the ROM can't be loaded", func(t *testing.T) {
+		c := New()
+		good := &ROM{Name: "good.ch8", Data: []uint8{0x00, 0xE0, 0x12, 0x02}}
+		assert.NoError(t, c.LoadROM(good))
+		c.Tick(0)
+		before := *c
+
+		huge := &ROM{Name: "huge.ch8", Data: make([]uint8, MemorySize-0x200+1), Info: &ROMInfo{
+			Platform: "superchip", Quirks: Quirks{Jump: true}, TickRate: 99, FontStyle: FontDream6800.Name,
+		}}
+		assert.True(t, errors.Is(c.LoadROM(huge), ErrROMTooLarge))
+		assert.Equal(t, before.Platform, c.Platform)
+		assert.Equal(t, before.Quirks, c.Quirks)
+		assert.Equal(t, before.TickRate, c.TickRate)
+		assert.Equal(t, before.Font, c.Font)
+		assert.Equal(t, before.CurrState, c.CurrState, "The program should still be in memory")
+		assert.Same(t, good, c.ROM)
+		assert.NoError(t, NewController(c).Reset(), "Resetting should load the last good ROM again")
+	})
+
+	t.Run("LoadROM should keep the platform selected for Octo cartridges, loading them where Octo assembles", func(t *testing.T) {
+		rom, err := ReadROM("cart.gif", bytes.NewReader(octoCartridge([]uint8(`{"program": ": main jump main"}`))))
+		assert.NoError(t, err)
+		c := New()
+		c.Platform = PlatformETI660
+		assert.NoError(t, c.LoadROM(rom))
+		assert.Equal(t, PlatformETI660.Name, c.Platform.Name)
+		assert.Equal(t, uint16(0x200), c.CurrState.PC)
+		assert.Equal(t, uint16(0x1200), c.CurrState.Opcode())
+	})
+
+	t.Run("LoadROM should move a font that would overlap the program to the default address", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.SelectFont(FontCOSMACVIP, 0x1B0))
+		rom := &ROM{Name: "schip.ch8", Data: []uint8{0x00, 0xE0, 0x12, 0x02}, Info: &ROMInfo{
+			Platform: "superchip", TickRate: DefaultTickRate, FontStyle: FontSuperChip.Name,
+		}}
+		assert.NoError(t, c.LoadROM(rom))
+		assert.Equal(t, FontsStartAddress, c.FontAddress)
+		assert.Equal(t, rom.Data, c.CurrState.Memory[0x200:0x204], "The big font shouldn't be written over the program")
+		assert.Equal(t, FontSuperChip.Big, c.CurrState.Memory[c.BigFontAddress():int(c.BigFontAddress())+len(FontSuperChip.Big)])
+	})
+
+	t.Run("LoadGame should refuse a selected font that overlaps the program", func(t *testing.T) {
+		c := New()
+		assert.NoError(t, c.SelectFont(FontSuperChip, 0x100))
+		c.Platform = Platform{Name: "low", LoadAddress: 0x120, MemorySize: MemorySize}
+		assert.Error(t, c.LoadGame([]uint8{0x00, 0xE0}))
+	})
+}
@@ -0,0 +1,233 @@
+package chip8
+
+import (
+	"crypto/sha1"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// database holds the ROM database, in the format of https://github.com/chip-8/chip-8-database
+//
+//go:embed database/*.json
+var database embed.FS
+
+// Database is the ROM database: programs, the index of their ROMs by SHA-1 and the platforms they run on
+type Database struct {
+	Programs  []DatabaseProgram
+	Hashes    map[string]int
+	Platforms map[string]DatabasePlatform
+}
+
+type DatabaseProgram struct {
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Release     string                 `json:"release"`
+	Authors     []string               `json:"authors"`
+	ROMs        map[string]DatabaseROM `json:"roms"`
+}
+
+type DatabaseROM struct {
+	File            string                    `json:"file"`
+	Platforms       []string                  `json:"platforms"`
+	QuirkyPlatforms map[string]DatabaseQuirks `json:"quirkyPlatforms"`
+	Tickrate        int                       `json:"tickrate"`
+	StartAddress    uint16                    `json:"startAddress"`
+	Keys            map[string]uint8          `json:"keys"`
+	FontStyle       string                    `json:"fontStyle"`
+	Colors          struct {
+		Pixels []string `json:"pixels"`
+	} `json:"colors"`
+}
+
+type DatabasePlatform struct {
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	DefaultTickrate int            `json:"defaultTickrate"`
+	Quirks          DatabaseQuirks `json:"quirks"`
+}
+
+// DatabaseQuirks: quirks as written on the database, nil when not given
+type DatabaseQuirks struct {
+	Shift                 *bool `json:"shift"`
+	MemoryIncrementByX    *bool `json:"memoryIncrementByX"`
+	MemoryLeaveIUnchanged *bool `json:"memoryLeaveIUnchanged"`
+	Wrap                  *bool `json:"wrap"`
+	Jump                  *bool `json:"jump"`
+	VBlank                *bool `json:"vblank"`
+	Logic                 *bool `json:"logic"`
+}
+
+// ROMInfo: what the database knows about a ROM, with the platform quirks already merged
+type ROMInfo struct {
+	SHA1     string
+	Title    string
+	Authors  []string
+	Release  string
+	Platform string
+	Quirks   Quirks
+	TickRate int
+	// StartAddress is 0 when the ROM loads at the platform default
+	StartAddress uint16
+	// Keys maps the database key names (up, down, left, right, a, b...) to chip-8 keys
+	Keys map[string]uint8
+	// Palette is the background color followed by the pixel colors, empty if there's no recommendation
+	Palette   []color.RGBA
+	FontStyle string
+}
+
+var (
+	defaultDatabase     *Database
+	defaultDatabaseErr  error
+	defaultDatabaseOnce sync.Once
+)
+
+// DefaultDatabase: the database embedded on the binary, parsed on the first use
+func DefaultDatabase() (*Database, error) {
+	defaultDatabaseOnce.Do(func() {
+		sub, err := fs.Sub(database, "database")
+		if err != nil {
+			defaultDatabaseErr = err
+			return
+		}
+		defaultDatabase, defaultDatabaseErr = LoadDatabase(sub)
+	})
+	return defaultDatabase, defaultDatabaseErr
+}
+
+// LoadDatabase: reads programs.json, sha1-hashes.json and platforms.json from the file system
+func LoadDatabase(fsys fs.FS) (*Database, error) {
+	db := &Database{}
+	if err := readDatabaseFile(fsys, "programs.json", &db.Programs); err != nil {
+		return nil, err
+	}
+	if err := readDatabaseFile(fsys, "sha1-hashes.json", &db.Hashes); err != nil {
+		return nil, err
+	}
+	var platforms []DatabasePlatform
+	if err := readDatabaseFile(fsys, "platforms.json", &platforms); err != nil {
+		return nil, err
+	}
+	db.Platforms = make(map[string]DatabasePlatform, len(platforms))
+	for _, platform := range platforms {
+		db.Platforms[platform.ID] = platform
+	}
+
+	for hash, index := range db.Hashes {
+		if index < 0 || index >= len(db.Programs) {
+			return nil, fmt.Errorf("ROM database: hash %s points to program %d, but there are %d programs", hash, index, len(db.Programs))
+		}
+	}
+	return db, nil
+}
+
+func readDatabaseFile(fsys fs.FS, name string, v interface{}) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("ROM database: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("ROM database %s: %w", name, err)
+	}
+	return nil
+}
+
+// Lookup: finds the ROM by the SHA-1 of its bytes, using the first platform it lists
+func (db *Database) Lookup(data []uint8) (*ROMInfo, bool) {
+	sum := sha1.Sum(data)
+	hash := hex.EncodeToString(sum[:])
+	index, ok := db.Hashes[hash]
+	if !ok {
+		return nil, false
+	}
+	program := db.Programs[index]
+	rom, ok := program.ROMs[hash]
+	if !ok {
+		return nil, false
+	}
+
+	info := &ROMInfo{
+		SHA1:         hash,
+		Title:        program.Title,
+		Authors:      program.Authors,
+		Release:      program.Release,
+		Quirks:       DefaultQuirks,
+		TickRate:     DefaultTickRate,
+		StartAddress: rom.StartAddress,
+		Keys:         rom.Keys,
+		FontStyle:    rom.FontStyle,
+	}
+	if len(rom.Platforms) > 0 {
+		info.Platform = rom.Platforms[0]
+		if platform, ok := db.Platforms[info.Platform]; ok {
+			info.Quirks = platform.Quirks.apply(Quirks{})
+			if platform.DefaultTickrate > 0 {
+				info.TickRate = platform.DefaultTickrate
+			}
+		}
+		info.Quirks = rom.QuirkyPlatforms[info.Platform].apply(info.Quirks)
+	}
+	if rom.Tickrate > 0 {
+		info.TickRate = rom.Tickrate
+	}
+	for _, pixel := range rom.Colors.Pixels {
+		if rgba, ok := parseHexColor(pixel); ok {
+			info.Palette = append(info.Palette, rgba)
+		}
+	}
+	return info, true
+}
+
+// apply: overrides the quirks that are set on the database
+func (q DatabaseQuirks) apply(quirks Quirks) Quirks {
+	set := func(dst *bool, src *bool) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	set(&quirks.Shift, q.Shift)
+	set(&quirks.MemoryIncrementByX, q.MemoryIncrementByX)
+	set(&quirks.MemoryLeaveIUnchanged, q.MemoryLeaveIUnchanged)
+	set(&quirks.Jump, q.Jump)
+	set(&quirks.Logic, q.Logic)
+	set(&quirks.Wrap, q.Wrap)
+	set(&quirks.DisplayWait, q.VBlank)
+	return quirks
+}
+
+// parseHexColor: parses colors written as #rrggbb
+func parseHexColor(s string) (color.RGBA, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, false
+	}
+	value, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}, true
+}
+
+// platform: the platform the interpreter emulates for the ROM, with its load address.
+// Info that doesn't name a platform, like the one of Octo cartridges, keeps the selected one.
+func (info *ROMInfo) platform(selected Platform) Platform {
+	platform := PlatformCHIP8
+	switch info.Platform {
+	case "":
+		platform = selected
+	case "chip48", "superchip1", "superchip":
+		platform = PlatformSuperChip
+	case "xochip":
+		platform = PlatformXOChip
+	}
+	if info.StartAddress != 0 {
+		platform.LoadAddress = info.StartAddress
+	}
+	return platform
+}
@@ -0,0 +1,90 @@
+package chip8
+
+import (
+	"bytes"
+	"image/color"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestROMDatabase(t *testing.T) {
+	t.Run("The embedded database should know every bundled ROM", func(t *testing.T) {
+		db, err := DefaultDatabase()
+		assert.NoError(t, err)
+
+		for _, file := range []string{"BLINKY.ch8", "connect4.ch8", "ibm.ch8", "invaders.ch8", "landing.ch8",
+			"maze.ch8", "pong.ch8", "random_number_test.ch8", "space.ch8", "tank.ch8", "test_opcode.ch8",
+			"tetris.ch8", "tictactoe.ch8", "wall.ch8"} {
+			data, err := os.ReadFile("../roms/" + file)
+			assert.NoError(t, err)
+			info, ok := db.Lookup(data)
+			if assert.True(t, ok, file) {
+				assert.NotEmpty(t, info.Title, file)
+				assert.Len(t, info.SHA1, 40, file)
+			}
+		}
+	})
+
+	t.Run("Lookup should merge the platform quirks with the ROM overrides", func(t *testing.T) {
+		db, err := LoadDatabase(fstest.MapFS{
+			"programs.json": {Data: []byte(`[{"title": "Game", "authors": ["Someone"], "roms": {
+				"da39a3ee5e6b4b0d3255bfef95601890afd80709": {
+					"platforms": ["chip48"],
+					"tickrate": 40,
+					"startAddress": 1536,
+					"quirkyPlatforms": {"chip48": {"jump": false}},
+					"keys": {"up": 2},
+					"colors": {"pixels": ["#102030", "#ffffff"]}
+				}}}]`)},
+			"sha1-hashes.json": {Data: []byte(`{"da39a3ee5e6b4b0d3255bfef95601890afd80709": 0}`)},
+			"platforms.json": {Data: []byte(`[{"id": "chip48", "defaultTickrate": 30,
+				"quirks": {"shift": true, "memoryIncrementByX": true, "jump": true}}]`)},
+		})
+		assert.NoError(t, err)
+
+		info, ok := db.Lookup(nil)
+		assert.True(t, ok)
+		assert.Equal(t, "Game", info.Title)
+		assert.Equal(t, Quirks{Shift: true, MemoryIncrementByX: true}, info.Quirks)
+		assert.Equal(t, 40, info.TickRate)
+		assert.Equal(t, uint8(2), info.Keys["up"])
+		assert.Equal(t, []color.RGBA{{R: 0x10, G: 0x20, B: 0x30, A: 255}, {R: 255, G: 255, B: 255, A: 255}}, info.Palette)
+		assert.Equal(t, uint16(0x600), info.platform(PlatformCHIP8).LoadAddress)
+
+		_, ok = db.Lookup([]uint8{0x00})
+		assert.False(t, ok, "Unknown ROMs should not be found")
+	})
+
+	t.Run("LoadDatabase should refuse hashes pointing to missing programs", func(t *testing.T) {
+		_, err := LoadDatabase(fstest.MapFS{
+			"programs.json":    {Data: []byte(`[]`)},
+			"sha1-hashes.json": {Data: []byte(`{"da39a3ee5e6b4b0d3255bfef95601890afd80709": 3}`)},
+			"platforms.json":   {Data: []byte(`[]`)},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("LoadROM should apply the settings of known ROMs", func(t *testing.T) {
+		data, err := os.ReadFile("../roms/pong.ch8")
+		assert.NoError(t, err)
+		rom, err := ReadROM("pong.ch8", bytes.NewReader(data))
+		assert.NoError(t, err)
+		if !assert.NotNil(t, rom.Info) {
+			return
+		}
+
+		c := New()
+		assert.NoError(t, c.LoadROM(rom))
+		assert.Equal(t, rom.Info.Quirks, c.Quirks)
+		assert.Equal(t, 15, c.TickRate)
+		assert.Equal(t, PlatformCHIP8, c.Platform)
+		assert.Same(t, rom, c.ROM)
+
+		rom, err = ReadROM("unknown.ch8", bytes.NewReader([]uint8{0x12, 0x00}))
+		assert.NoError(t, err)
+		assert.Nil(t, rom.Info)
+	})
+}
@@ -0,0 +1,33 @@
+package chip8_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/franciscocid/chip-8/chip8"
+	"github.com/franciscocid/chip-8/chip8/chip8test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPongScore(t *testing.T) {
+	t.Run("Pong should score a point when the ball passes a paddle", func(t *testing.T) {
+		rom, err := chip8.LoadROMFile("../roms/pong.ch8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rand.Seed(1)
+		c := chip8.New()
+		assert.NoError(t, c.LoadROM(rom))
+		ctl := chip8.NewController(c)
+		for i := 0; i < 300; i++ {
+			ctl.StepFrame()
+		}
+
+		scores := image.Rect(0, 0, chip8.ScreenWidth, 6)
+		left, right := scores, scores
+		left.Max.X, right.Min.X = chip8.ScreenWidth/2, chip8.ScreenWidth/2
+		assert.Equal(t, "1", chip8test.ReadHex(c, left), "Nobody moved the right paddle")
+		assert.Equal(t, "0", chip8test.ReadHex(c, right))
+	})
+}
@@ -0,0 +1,203 @@
+package chip8
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sort"
+)
+
+type Sprite struct {
+	Address uint16
+	Height  uint8
+	Data    []uint8
+}
+
+const (
+	spriteAtlasColumns = 8
+	spriteMaxHeight    = 15
+	fontGlyphHeight    = 5
+)
+
+// FindSprites: statically scans the ROM for Annn instructions followed by Dxyn,
+// which point to the sprites the ROM draws. Addresses are absolute, so loadAddress
+// is where the ROM starts in memory.
+func FindSprites(rom []uint8, loadAddress uint16) []Sprite {
+	collector := newSpriteCollector()
+	iKnown := false
+	var i uint16
+
+	for offset := 0; offset+1 < len(rom); offset += 2 {
+		opcode := uint16(rom[offset])<<ByteSize | uint16(rom[offset+1])
+		switch {
+		case opcode&0xF000 == 0xA000:
+			i, iKnown = opcode&0x0FFF, true
+		case opcode&0xF000 == 0xD000:
+			if iKnown {
+				collector.add(i, uint8(opcode&0x000F))
+			}
+		case opcode&0xF0FF == 0xF01E, opcode&0xF0FF == 0xF029:
+			iKnown = false
+		case opcode == 0x00EE, opcode&0xF000 == 0x1000, opcode&0xF000 == 0x2000, opcode&0xF000 == 0xB000:
+			// I may hold anything after the control flow changes
+			iKnown = false
+		}
+	}
+
+	return collector.sprites(func(addr uint16, height uint8) []uint8 {
+		start := int(addr) - int(loadAddress)
+		if start < 0 || start >= len(rom) {
+			return nil
+		}
+		end := start + int(height)
+		if end > len(rom) {
+			end = len(rom)
+		}
+		return append([]uint8{}, rom[start:end]...)
+	})
+}
+
+// SpriteRecorder collects the sprites drawn by a running Chip8
+type SpriteRecorder struct {
+	c8        *Chip8
+	collector spriteCollector
+}
+
+// RecordSprites: starts recording every sprite drawn by the Chip8
+func RecordSprites(c8 *Chip8) *SpriteRecorder {
+	r := &SpriteRecorder{c8: c8, collector: newSpriteCollector()}
+	previous := c8.Hooks.SpriteDrawn
+	c8.Hooks.SpriteDrawn = func(addr uint16, height uint8) {
+		if previous != nil {
+			previous(addr, height)
+		}
+		r.collector.add(addr, height)
+	}
+	return r
+}
+
+// Sprites: the sprites drawn so far, with their data read from the current memory
+func (r *SpriteRecorder) Sprites() []Sprite {
+	memory := r.c8.CurrState.Memory[:]
+	return r.collector.sprites(func(addr uint16, height uint8) []uint8 {
+		data := make([]uint8, 0, height)
+		for row := 0; row < int(height) && int(addr)+row < len(memory); row++ {
+			data = append(data, memory[int(addr)+row])
+		}
+		return data
+	})
+}
+
+// FontSprites: the glyphs of the font loaded by LoadFonts, the small font from 0 to F
+// followed by the big font if there's one
+func (c *Chip8) FontSprites() []Sprite {
+	sprites := make([]Sprite, 0, 0x20)
+	glyphs := func(start uint16, size int, height uint8) {
+		for addr := start; addr < start+uint16(size); addr += uint16(height) {
+			sprites = append(sprites, Sprite{
+				Address: addr,
+				Height:  height,
+				Data:    append([]uint8{}, c.CurrState.Memory[addr:addr+uint16(height)]...),
+			})
+		}
+	}
+	glyphs(c.FontAddress, len(c.Font.Small), fontGlyphHeight)
+	glyphs(c.BigFontAddress(), len(c.Font.Big), bigFontGlyphSize)
+	return sprites
+}
+
+// RenderSpriteAtlas: draws the sprites side by side, spriteAtlasColumns per row,
+// each on a cell of 8 by 15 pixels with 1 pixel of spacing
+func RenderSpriteAtlas(sprites []Sprite, scale int) *image.RGBA {
+	if scale < 1 {
+		scale = 1
+	}
+	columns := spriteAtlasColumns
+	if len(sprites) < columns {
+		columns = len(sprites)
+	}
+	rows := (len(sprites) + spriteAtlasColumns - 1) / spriteAtlasColumns
+	cellW, cellH := (ByteSize+1)*scale, (spriteMaxHeight+1)*scale
+
+	atlas := image.NewRGBA(image.Rect(0, 0, columns*cellW, rows*cellH))
+	background := color.RGBA{R: 40, G: 40, B: 40, A: 255}
+	for y := 0; y < atlas.Bounds().Dy(); y++ {
+		for x := 0; x < atlas.Bounds().Dx(); x++ {
+			atlas.SetRGBA(x, y, background)
+		}
+	}
+
+	for i, sprite := range sprites {
+		originX, originY := (i%spriteAtlasColumns)*cellW, (i/spriteAtlasColumns)*cellH
+		for row, value := range sprite.Data {
+			for col := 0; col < ByteSize; col++ {
+				pixel := color.RGBA{A: 255}
+				if value&(FirstFontBitMask>>col) != 0 {
+					pixel = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+				}
+				for sy := 0; sy < scale; sy++ {
+					for sx := 0; sx < scale; sx++ {
+						atlas.SetRGBA(originX+col*scale+sx, originY+row*scale+sy, pixel)
+					}
+				}
+			}
+		}
+	}
+	return atlas
+}
+
+// WriteSpritesPNG: exports the sprites as a PNG atlas
+func WriteSpritesPNG(w io.Writer, sprites []Sprite, scale int) error {
+	return png.Encode(w, RenderSpriteAtlas(sprites, scale))
+}
+
+// WriteSpritesAssembly: exports the sprites as assembler source, one db per row
+func WriteSpritesAssembly(w io.Writer, sprites []Sprite) error {
+	for _, sprite := range sprites {
+		if _, err := fmt.Fprintf(w, "sprite_%03X: ; %d bytes\n", sprite.Address, len(sprite.Data)); err != nil {
+			return err
+		}
+		for _, value := range sprite.Data {
+			if _, err := fmt.Fprintf(w, "\tdb 0x%02X ; %s\n", value, spriteRowString(value)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spriteCollector: keeps the tallest height seen for each sprite address
+type spriteCollector map[uint16]uint8
+
+func newSpriteCollector() spriteCollector {
+	return spriteCollector{}
+}
+
+func (sc spriteCollector) add(addr uint16, height uint8) {
+	if height == 0 {
+		return
+	}
+	if height > sc[addr] {
+		sc[addr] = height
+	}
+}
+
+func (sc spriteCollector) sprites(read func(addr uint16, height uint8) []uint8) []Sprite {
+	sprites := make([]Sprite, 0, len(sc))
+	for addr, height := range sc {
+		data := read(addr, height)
+		if len(data) == 0 {
+			continue
+		}
+		sprites = append(sprites, Sprite{Address: addr, Height: height, Data: data})
+	}
+	sort.Slice(sprites, func(i, j int) bool {
+		return sprites[i].Address < sprites[j].Address
+	})
+	return sprites
+}
@@ -0,0 +1,54 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSprites(t *testing.T) {
+	rom := []uint8{
+		0xA2, 0x08, // LD I, 0x208
+		0xD0, 0x12, // DRW V0, V1, 2
+		0xF0, 0x1E, // ADD I, V0
+		0xD0, 0x13, // DRW V0, V1, 3 (I is unknown here)
+		0xF0, 0x90, // sprite data
+	}
+
+	t.Run("FindSprites should find the sprites pointed by Annn and drawn by Dxyn", func(t *testing.T) {
+		sprites := FindSprites(rom, ProgramStartAddress)
+		assert.Equal(t, []Sprite{{Address: 0x208, Height: 2, Data: []uint8{0xF0, 0x90}}}, sprites)
+	})
+
+	t.Run("RecordSprites should collect the sprites drawn at runtime", func(t *testing.T) {
+		c := New()
+		c.LoadGame(rom)
+		r := RecordSprites(c)
+		c.Tick(0)
+		c.Tick(0)
+		assert.Equal(t, []Sprite{{Address: 0x208, Height: 2, Data: []uint8{0xF0, 0x90}}}, r.Sprites())
+	})
+
+	t.Run("FontSprites should return every glyph of the font", func(t *testing.T) {
+		c := New()
+		c.LoadFonts()
+		sprites := c.FontSprites()
+		assert.Len(t, sprites, 0x20, "Should have the small and the big font")
+		assert.Equal(t, FontsStartAddress+5*0xA, sprites[0xA].Address)
+		assert.Equal(t, []uint8{0xF0, 0x90, 0xF0, 0x90, 0x90}, sprites[0xA].Data)
+	})
+
+	t.Run("Sprites should be exported as atlas and assembler source", func(t *testing.T) {
+		sprites := []Sprite{{Address: 0x208, Height: 2, Data: []uint8{0xF0, 0x90}}}
+
+		atlas := RenderSpriteAtlas(sprites, 2)
+		assert.Equal(t, 18, atlas.Bounds().Dx())
+		assert.Equal(t, uint8(255), atlas.RGBAAt(0, 0).R, "Lit sprite pixel should be white")
+		assert.Equal(t, uint8(0), atlas.RGBAAt(8, 0).R, "Unlit sprite pixel should be black")
+
+		var asm bytes.Buffer
+		assert.NoError(t, WriteSpritesAssembly(&asm, sprites))
+		assert.Equal(t, "sprite_208: ; 2 bytes\n\tdb 0xF0 ; ####....\n\tdb 0x90 ; #..#....\n\n", asm.String())
+	})
+}
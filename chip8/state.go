@@ -2,20 +2,22 @@ package chip8
 
 type State struct {
 	V          [0x10]uint8
-	Memory     [0xFFF]uint8
+	Memory     [MemorySize]uint8
 	I          uint16
 	PC         uint16
 	DelayTimer uint8
 	SoundTimer uint8
 	SP         uint8
-	Stack      [0xF]uint16
+	Stack      [StackSize]uint16
 	Graphics   [ScreenHeight]uint64
 	Keyboard   [0x10]bool
 }
 
+// Opcode: the opcode at PC, wrapping around the end of memory. It doesn't go through
+// the Chip8 accessors, so it's safe to call from tools without triggering hooks or faults.
 func (s *State) Opcode() uint16 {
-	mostSignificantByte := uint16(s.Memory[s.PC]) << 8
-	lessSignificantByte := uint16(s.Memory[s.PC+1])
+	mostSignificantByte := uint16(s.Memory[s.PC&AddressMask]) << ByteSize
+	lessSignificantByte := uint16(s.Memory[(s.PC+1)&AddressMask])
 	return mostSignificantByte | lessSignificantByte
 }
 
@@ -0,0 +1,168 @@
+package chip8
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// TimendusTest is a ROM of the Timendus chip-8 test suite (https://github.com/Timendus/chip8-test-suite)
+// and what it needs to run headless. The suite shows a check mark next to every check that passed
+// and a cross next to every check that failed, which is how the results are read back from the screen.
+type TimendusTest struct {
+	Name string
+	// File is the name of the ROM on the suite
+	File string
+	// Platform is the platform of the ROM database whose quirks and tick rate are used
+	Platform string
+	// Menu is poked at 0x1FF before running, the suite reads it to skip its menu. 0 shows the menu.
+	Menu   uint8
+	Frames int
+}
+
+// timendusMenuAddress: where the suite looks for an option picked before it starts
+const timendusMenuAddress = 0x1FF
+
+// TimendusTests: the tests of the suite that report checks. The quirks test only runs its chip-8
+// option, the SCHIP and XO-CHIP ones need opcodes this core doesn't have. The keypad test isn't
+// one of them, it reports in text and needs someone at the keys.
+var TimendusTests = []TimendusTest{
+	{Name: "corax+", File: "3-corax+.ch8", Platform: "modernChip8", Frames: 120},
+	{Name: "flags", File: "4-flags.ch8", Platform: "modernChip8", Frames: 240},
+	{Name: "quirks chip-8", File: "5-quirks.ch8", Platform: "originalChip8", Menu: 1, Frames: 600},
+}
+
+// TimendusCheck: a result shown on the screen, X and Y are where its mark is
+type TimendusCheck struct {
+	X, Y   int
+	Passed bool
+}
+
+func (check TimendusCheck) String() string {
+	result := "failed"
+	if check.Passed {
+		result = "passed"
+	}
+	return fmt.Sprintf("%d,%d %s", check.X, check.Y, result)
+}
+
+// TimendusResult: the checks left on the screen after running a test
+type TimendusResult struct {
+	Test   TimendusTest
+	Checks []TimendusCheck
+	Screen State
+	Fault  error
+}
+
+// Passed: true when the test showed some checks and all of them passed
+func (r *TimendusResult) Passed() bool {
+	if r.Fault != nil || len(r.Checks) == 0 {
+		return false
+	}
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed: the checks that failed
+func (r *TimendusResult) Failed() []TimendusCheck {
+	var failed []TimendusCheck
+	for _, check := range r.Checks {
+		if !check.Passed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+var (
+	// timendusPassMark and timendusFailMark: the check mark and the cross of the suite, a byte per row
+	timendusPassMark = []uint8{0x02, 0x04, 0x88, 0x50, 0x20}
+	timendusFailMark = []uint8{0x88, 0x50, 0x20, 0x50, 0x88}
+)
+
+// RunTimendusTest: runs the ROM of the test on a new machine with the quirks of its platform,
+// and reads the checks from the screen
+func RunTimendusTest(rom *ROM, test TimendusTest) (*TimendusResult, error) {
+	db, err := DefaultDatabase()
+	if err != nil {
+		return nil, err
+	}
+	platform, ok := db.Platforms[test.Platform]
+	if !ok {
+		return nil, fmt.Errorf("unknown platform %q for the %s test", test.Platform, test.Name)
+	}
+
+	c := New()
+	c.Quirks = platform.Quirks.apply(Quirks{})
+	if platform.DefaultTickrate > 0 {
+		c.TickRate = platform.DefaultTickrate
+	}
+	if err := c.LoadGame(rom.Data); err != nil {
+		return nil, err
+	}
+	if test.Menu != 0 {
+		c.WriteMemory(timendusMenuAddress, test.Menu)
+	}
+
+	ctl := NewController(c)
+	for frame := 0; frame < test.Frames && c.Fault == nil; frame++ {
+		ctl.StepFrame()
+	}
+
+	return &TimendusResult{
+		Test:   test,
+		Checks: TimendusChecks(&c.CurrState),
+		Screen: c.CurrState,
+		Fault:  c.Fault,
+	}, nil
+}
+
+// TimendusChecks: finds the check marks and crosses on the screen, top to bottom and left to right
+func TimendusChecks(s *State) []TimendusCheck {
+	var checks []TimendusCheck
+	for y := 0; y < ScreenHeight; y++ {
+		for x := 0; x < ScreenWidth; x++ {
+			switch {
+			case matchMark(s, x, y, timendusPassMark):
+				checks = append(checks, TimendusCheck{X: x, Y: y, Passed: true})
+			case matchMark(s, x, y, timendusFailMark):
+				checks = append(checks, TimendusCheck{X: x, Y: y})
+			}
+		}
+	}
+	return checks
+}
+
+// matchMark: true if the mark is drawn with its top left corner at x, y and nothing touches it,
+// so marks aren't mistaken for a part of a bigger drawing
+func matchMark(s *State, x, y int, mark []uint8) bool {
+	width := 0
+	for _, row := range mark {
+		if w := ByteSize - bits.TrailingZeros8(row); row != 0 && w > width {
+			width = w
+		}
+	}
+	if x+width > ScreenWidth || y+len(mark) > ScreenHeight {
+		return false
+	}
+
+	for dy := -1; dy <= len(mark); dy++ {
+		for dx := -1; dx <= width; dx++ {
+			px, py := x+dx, y+dy
+			if px < 0 || py < 0 || px >= ScreenWidth || py >= ScreenHeight {
+				continue
+			}
+			want := false
+			if dy >= 0 && dy < len(mark) && dx >= 0 && dx < width {
+				want = mark[dy]&(FirstFontBitMask>>dx) != 0
+			}
+			if s.GetPixel(uint8(px), uint8(py)) != want {
+				return false
+			}
+		}
+	}
+	return true
+}
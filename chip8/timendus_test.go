@@ -0,0 +1,79 @@
+package chip8
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// drawMark: draws the rows of a mark like Dxyn would on an empty screen
+func drawMark(s *State, x, y int, mark []uint8) {
+	for row, value := range mark {
+		for col := 0; col < ByteSize; col++ {
+			if value&(FirstFontBitMask>>col) != 0 {
+				s.SetPixel(uint8(x+col), uint8(y+row))
+			}
+		}
+	}
+}
+
+func TestTimendus(t *testing.T) {
+	t.Run("TimendusChecks should read the marks in reading order", func(t *testing.T) {
+		var s State
+		drawMark(&s, 40, 2, timendusFailMark)
+		drawMark(&s, 10, 2, timendusPassMark)
+		drawMark(&s, 0, 20, timendusPassMark)
+
+		assert.Equal(t, []TimendusCheck{
+			{X: 10, Y: 2, Passed: true},
+			{X: 40, Y: 2},
+			{X: 0, Y: 20, Passed: true},
+		}, TimendusChecks(&s))
+	})
+
+	t.Run("TimendusChecks should ignore marks that are part of a bigger drawing", func(t *testing.T) {
+		var s State
+		drawMark(&s, 10, 2, timendusPassMark)
+		s.SetPixel(17, 4)
+		assert.Empty(t, TimendusChecks(&s))
+	})
+
+	t.Run("RunTimendusTest should pick the menu option and read the results", func(t *testing.T) {
+		rom := &ROM{Name: "marks.ch8", Data: []uint8{
+			0xA1, 0xFF, // 200: LD I, 0x1FF
+			0xF0, 0x65, // 202: LD V0, [I]
+			0x30, 0x02, // 204: SE V0, 2, the option picked on the menu
+			0x12, 0x06, // 206: JP 0x206
+			0xA2, 0x18, // 208: LD I, pass mark
+			0x60, 0x08, // 20A: LD V0, 8
+			0x61, 0x04, // 20C: LD V1, 4
+			0xD0, 0x15, // 20E: DRW V0, V1, 5
+			0xA2, 0x1D, // 210: LD I, fail mark
+			0x60, 0x18, // 212: LD V0, 24
+			0xD0, 0x15, // 214: DRW V0, V1, 5
+			0x12, 0x16, // 216: JP 0x216
+			0x02, 0x04, 0x88, 0x50, 0x20, // 218: pass mark
+			0x88, 0x50, 0x20, 0x50, 0x88, // 21D: fail mark
+		}}
+		test := TimendusTest{Name: "marks", Platform: "modernChip8", Menu: 2, Frames: 10}
+
+		result, err := RunTimendusTest(rom, test)
+		assert.NoError(t, err)
+		assert.Equal(t, []TimendusCheck{{X: 8, Y: 4, Passed: true}, {X: 24, Y: 4}}, result.Checks)
+		assert.Equal(t, []TimendusCheck{{X: 24, Y: 4}}, result.Failed())
+		assert.False(t, result.Passed())
+	})
+
+	for _, test := range TimendusTests {
+		t.Run("The suite should pass "+test.Name, func(t *testing.T) {
+			rom, err := LoadROMFile(filepath.Join("testdata", "timendus", test.File))
+			if err != nil {
+				t.Skipf("%s isn't in testdata/timendus: %v", test.File, err)
+			}
+			result, err := RunTimendusTest(rom, test)
+			assert.NoError(t, err)
+			assert.True(t, result.Passed(), "%v failed\n%s", result.Failed(), screenString(result.Screen))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package chip8
+
+import "fmt"
+
+// TimingMode decides how much time each instruction takes
+type TimingMode int
+
+const (
+	// TimingFixed: every instruction takes one tick, TickRate ticks per frame
+	TimingFixed TimingMode = iota
+	// TimingCOSMACVIP: every instruction takes the machine cycles it took on the COSMAC VIP
+	// interpreter, and Dxyn waits for the vertical blank before drawing like it did
+	TimingCOSMACVIP
+)
+
+// TimingModes: the timing modes by name
+var TimingModes = map[string]TimingMode{
+	"fixed": TimingFixed,
+	"vip":   TimingCOSMACVIP,
+}
+
+func (t TimingMode) String() string {
+	for name, mode := range TimingModes {
+		if mode == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("TimingMode(%d)", int(t))
+}
+
+const (
+	// VIPClockHz: the clock of the CDP1802 on the COSMAC VIP
+	VIPClockHz = 1760000
+	// VIPClocksPerCycle: clock pulses in each machine cycle of the CDP1802
+	VIPClocksPerCycle = 8
+	// VIPCyclesPerFrame: the machine cycles run on every 60Hz frame
+	VIPCyclesPerFrame = float64(VIPClockHz) / VIPClocksPerCycle / FrameRate
+
+	// vipFetchCycles: fetching and decoding, paid by every instruction
+	vipFetchCycles = 40
+)
+
+// vipCycles: the machine cycles the interpreter of the COSMAC VIP took to run the opcode
+// on the current state, fetch and decode included. Costs are approximated from the routines
+// of the interpreter; CLS, Dxyn, Fx33, Fx55 and Fx65 vary with their operands like on it.
+func (c *Chip8) vipCycles(opcode uint16) int {
+	s := &c.CurrState
+	x := opcode & 0x0F00 >> ByteSize
+	n := int(opcode & 0x000F)
+
+	switch opcode & 0xF000 {
+	case 0x0000:
+		switch opcode {
+		case 0x00E0:
+			// Clears the 256 bytes of the display buffer one at a time
+			return vipFetchCycles + 24 + 256*12
+		case 0x00EE:
+			return vipFetchCycles + 10
+		}
+		return vipFetchCycles
+	case 0x1000:
+		return vipFetchCycles + 12
+	case 0x2000:
+		return vipFetchCycles + 26
+	case 0x3000, 0x4000:
+		return vipFetchCycles + 10
+	case 0x5000, 0x9000:
+		return vipFetchCycles + 14
+	case 0x6000:
+		return vipFetchCycles + 6
+	case 0x7000:
+		return vipFetchCycles + 10
+	case 0x8000:
+		return vipFetchCycles + 44
+	case 0xA000:
+		return vipFetchCycles + 12
+	case 0xB000:
+		return vipFetchCycles + 22
+	case 0xC000:
+		return vipFetchCycles + 36
+	case 0xD000:
+		// Every row is shifted bit by bit to the column, and spills into a second
+		// byte of the display buffer when the column isn't aligned
+		shift := int(s.V[x] % ByteSize)
+		row := 34 + 8*shift
+		if shift != 0 {
+			row += 12
+		}
+		return vipFetchCycles + 26 + n*row
+	case 0xE000:
+		return vipFetchCycles + 14
+	}
+
+	switch opcode & 0xF0FF {
+	case 0xF01E, 0xF029:
+		return vipFetchCycles + 16
+	case 0xF033:
+		// The digits are found by repeated subtraction
+		value := int(s.V[x])
+		return vipFetchCycles + 84 + 16*(value/100+value/10%10+value%10)
+	case 0xF055, 0xF065:
+		return vipFetchCycles + 14 + 14*(int(x)+1)
+	}
+	return vipFetchCycles + 10
+}
+
+// EndFrame: marks the vertical blank between two 60Hz frames, releasing a Dxyn waiting
+// for the display. A Dxyn that gets to run later in the frame still waits for the next
+// one. With COSMAC VIP timing the timers count down here too.
+func (c *Chip8) EndFrame() {
+	if c.Timing == TimingCOSMACVIP {
+		c.countDownTimers(&c.CurrState)
+	}
+	c.vblank = c.waitingForVBlank
+	c.waitingForVBlank = false
+}
+
+// WaitingForVBlank: true while a Dxyn is waiting for the next frame to draw
+func (c *Chip8) WaitingForVBlank() bool {
+	return c.waitingForVBlank
+}
+
+// waitForVBlank: returns true if the opcode has to wait for the vertical blank before running,
+// which Dxyn does with COSMAC VIP timing or the display wait quirk
+func (c *Chip8) waitForVBlank(opcode uint16) bool {
+	if opcode&0xF000 != 0xD000 || (c.Timing != TimingCOSMACVIP && !c.Quirks.DisplayWait) {
+		return false
+	}
+	if c.vblank {
+		c.vblank = false
+		return false
+	}
+	c.waitingForVBlank = true
+	return true
+}
@@ -0,0 +1,113 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTiming(t *testing.T) {
+	t.Run("VIP timing should charge the machine cycles of each instruction", func(t *testing.T) {
+		c := New()
+		c.Timing = TimingCOSMACVIP
+		assert.NoError(t, c.LoadGame([]uint8{0x60, 0x05, 0x70, 0x01}))
+
+		c.Tick(0)
+		assert.Equal(t, int64(vipFetchCycles+6), c.Cycles)
+		c.Tick(0)
+		assert.Equal(t, int64(2*vipFetchCycles+16), c.Cycles)
+	})
+
+	t.Run("Dxyn should cost more when the column isn't byte aligned", func(t *testing.T) {
+		c := New()
+		c.CurrState.V[0x0] = 8
+		aligned := c.vipCycles(0xD005)
+		c.CurrState.V[0x0] = 9
+		shifted := c.vipCycles(0xD005)
+		assert.Greater(t, shifted, aligned)
+		assert.Greater(t, c.vipCycles(0xD00F), c.vipCycles(0xD001), "Taller sprites should cost more")
+	})
+
+	t.Run("Fx33 should cost more for bigger digits", func(t *testing.T) {
+		c := New()
+		c.CurrState.V[0x0] = 100
+		small := c.vipCycles(0xF033)
+		c.CurrState.V[0x0] = 199
+		assert.Greater(t, c.vipCycles(0xF033), small)
+	})
+
+	t.Run("Dxyn should wait for the vertical blank with VIP timing", func(t *testing.T) {
+		c := New()
+		c.Timing = TimingCOSMACVIP
+		assert.NoError(t, c.LoadGame([]uint8{0xD0, 0x01, 0xD0, 0x01}))
+
+		c.Tick(0)
+		assert.True(t, c.WaitingForVBlank())
+		assert.Equal(t, uint16(ProgramStartAddress), c.CurrState.PC, "Dxyn should not run before the vertical blank")
+
+		c.EndFrame()
+		assert.False(t, c.WaitingForVBlank())
+		c.Tick(0)
+		assert.Equal(t, uint16(ProgramStartAddress+2), c.CurrState.PC)
+		c.Tick(0)
+		assert.True(t, c.WaitingForVBlank(), "Only one Dxyn should run on each frame")
+	})
+
+	t.Run("Dxyn should wait for the next vertical blank after other instructions ran in the frame", func(t *testing.T) {
+		c := New()
+		c.Timing = TimingCOSMACVIP
+		assert.NoError(t, c.LoadGame([]uint8{0x60, 0x01, 0x70, 0x01, 0xD0, 0x01}))
+
+		c.Tick(0)
+		c.EndFrame()
+		c.Tick(0)
+		c.Tick(0)
+		assert.True(t, c.WaitingForVBlank(), "A vertical blank with no Dxyn waiting shouldn't let a later one draw")
+		assert.Equal(t, uint16(ProgramStartAddress+4), c.CurrState.PC)
+
+		c.EndFrame()
+		c.Tick(0)
+		assert.Equal(t, uint16(ProgramStartAddress+6), c.CurrState.PC)
+	})
+
+	t.Run("Timers should count down on EndFrame with VIP timing", func(t *testing.T) {
+		c := New()
+		c.Timing = TimingCOSMACVIP
+		assert.NoError(t, c.LoadGame([]uint8{0x12, 0x00}))
+		c.CurrState.DelayTimer = 5
+
+		for i := 0; i < 100; i++ {
+			c.Tick(0)
+		}
+		assert.Equal(t, uint8(5), c.CurrState.DelayTimer, "Instructions should not count the timers down")
+		c.EndFrame()
+		assert.Equal(t, uint8(4), c.CurrState.DelayTimer)
+	})
+
+	t.Run("The controller should spend the VIP cycle budget on every frame", func(t *testing.T) {
+		c := New()
+		c.Timing = TimingCOSMACVIP
+		assert.NoError(t, c.LoadGame([]uint8{0x70, 0x01, 0x12, 0x00}))
+		ctl := NewController(c)
+
+		ctl.StepFrame()
+		assert.InDelta(t, VIPCyclesPerFrame, float64(c.Cycles), vipFetchCycles+12, "A frame should run about a frame of cycles")
+		assert.Equal(t, float64(VIPClockHz), ctl.ClockHz())
+	})
+
+	t.Run("The controller should end the frame when Dxyn waits", func(t *testing.T) {
+		c := New()
+		c.Timing = TimingCOSMACVIP
+		assert.NoError(t, c.LoadGame([]uint8{0xD0, 0x01, 0x12, 0x00}))
+		ctl := NewController(c)
+		var frameTicks []int
+		ctl.Frame = func(ticks int) {
+			frameTicks = append(frameTicks, ticks)
+		}
+
+		ctl.StepFrame()
+		ctl.StepFrame()
+		assert.Equal(t, 0, frameTicks[0], "The first frame should only wait for the display")
+		assert.Greater(t, frameTicks[1], 1, "The next frame should draw and carry on")
+	})
+}
@@ -0,0 +1,209 @@
+package chip8
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TraceEntry is the machine right before an instruction runs.
+//
+// Traces are text, an entry per line with its fields separated by spaces:
+//
+//	cycle pc opcode v0 v1 v2 v3 v4 v5 v6 v7 v8 v9 va vb vc vd ve vf i sp dt st
+//
+// The cycle is a decimal count of the instructions run before it, starting at 0. Every
+// other field is hexadecimal without a prefix: pc, opcode and i take up to 4 digits and
+// the registers, sp and the timers up to 2. Blank lines and lines starting with # are ignored,
+// so a trace can start with a header saying where it comes from:
+//
+//	# pong.ch8 on some emulator
+//	0 200 6a02 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 0000 00 00 00
+type TraceEntry struct {
+	Cycle      int64
+	PC         uint16
+	Opcode     uint16
+	V          [0x10]uint8
+	I          uint16
+	SP         uint8
+	DelayTimer uint8
+	SoundTimer uint8
+}
+
+// traceFields: how many fields there are on every line of a trace
+const traceFields = 3 + 0x10 + 4
+
+var ErrTraceFormat = errors.New("invalid trace line")
+
+// NewTraceEntry: the entry of the state before running the opcode at PC
+func NewTraceEntry(cycle int64, s *State) TraceEntry {
+	return TraceEntry{
+		Cycle:      cycle,
+		PC:         s.PC,
+		Opcode:     s.Opcode(),
+		V:          s.V,
+		I:          s.I,
+		SP:         s.SP,
+		DelayTimer: s.DelayTimer,
+		SoundTimer: s.SoundTimer,
+	}
+}
+
+func (e TraceEntry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %03x %04x", e.Cycle, e.PC, e.Opcode)
+	for _, v := range e.V {
+		fmt.Fprintf(&b, " %02x", v)
+	}
+	fmt.Fprintf(&b, " %04x %02x %02x %02x", e.I, e.SP, e.DelayTimer, e.SoundTimer)
+	return b.String()
+}
+
+// Diff: the names of the fields that differ from the other entry, empty when they're equal
+func (e TraceEntry) Diff(other TraceEntry) []string {
+	var fields []string
+	check := func(name string, equal bool) {
+		if !equal {
+			fields = append(fields, name)
+		}
+	}
+	check("cycle", e.Cycle == other.Cycle)
+	check("pc", e.PC == other.PC)
+	check("opcode", e.Opcode == other.Opcode)
+	for x := range e.V {
+		check(fmt.Sprintf("v%x", x), e.V[x] == other.V[x])
+	}
+	check("i", e.I == other.I)
+	check("sp", e.SP == other.SP)
+	check("dt", e.DelayTimer == other.DelayTimer)
+	check("st", e.SoundTimer == other.SoundTimer)
+	return fields
+}
+
+// ParseTraceEntry: reads an entry from a line of a trace
+func ParseTraceEntry(line string) (TraceEntry, error) {
+	fields := strings.Fields(line)
+	if len(fields) != traceFields {
+		return TraceEntry{}, fmt.Errorf("%w: it has %d fields instead of %d", ErrTraceFormat, len(fields), traceFields)
+	}
+
+	var e TraceEntry
+	var err error
+	parse := func(field string, bits int) uint64 {
+		if err != nil {
+			return 0
+		}
+		var value uint64
+		value, err = strconv.ParseUint(field, 16, bits)
+		if err != nil {
+			err = fmt.Errorf("%w: %v", ErrTraceFormat, err)
+		}
+		return value
+	}
+
+	if e.Cycle, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+		return TraceEntry{}, fmt.Errorf("%w: %v", ErrTraceFormat, err)
+	}
+	e.PC = uint16(parse(fields[1], 16))
+	e.Opcode = uint16(parse(fields[2], 16))
+	for x := range e.V {
+		e.V[x] = uint8(parse(fields[3+x], 8))
+	}
+	e.I = uint16(parse(fields[19], 16))
+	e.SP = uint8(parse(fields[20], 8))
+	e.DelayTimer = uint8(parse(fields[21], 8))
+	e.SoundTimer = uint8(parse(fields[22], 8))
+	if err != nil {
+		return TraceEntry{}, err
+	}
+	return e, nil
+}
+
+// TraceWriter writes trace entries, a line each
+type TraceWriter struct {
+	w *bufio.Writer
+}
+
+func NewTraceWriter(w io.Writer) *TraceWriter {
+	return &TraceWriter{w: bufio.NewWriter(w)}
+}
+
+// Comment: writes a line that readers skip
+func (t *TraceWriter) Comment(text string) error {
+	_, err := fmt.Fprintf(t.w, "# %s\n", text)
+	return err
+}
+
+func (t *TraceWriter) Write(e TraceEntry) error {
+	_, err := fmt.Fprintln(t.w, e)
+	return err
+}
+
+// Flush: writes what's buffered, it has to be called when the trace is done
+func (t *TraceWriter) Flush() error {
+	return t.w.Flush()
+}
+
+// TraceReader reads the entries of a trace one at a time
+type TraceReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewTraceReader(r io.Reader) *TraceReader {
+	return &TraceReader{scanner: bufio.NewScanner(r)}
+}
+
+// Read: the next entry, io.EOF when the trace is over
+func (t *TraceReader) Read() (TraceEntry, error) {
+	for t.scanner.Scan() {
+		t.line++
+		line := strings.TrimSpace(t.scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, err := ParseTraceEntry(line)
+		if err != nil {
+			return TraceEntry{}, fmt.Errorf("line %d: %w", t.line, err)
+		}
+		return e, nil
+	}
+	if err := t.scanner.Err(); err != nil {
+		return TraceEntry{}, err
+	}
+	return TraceEntry{}, io.EOF
+}
+
+// Line: the line of the last entry read
+func (t *TraceReader) Line() int {
+	return t.line
+}
+
+// TraceRecorder counts the instructions run by a Chip8 and passes an entry for each to a function
+type TraceRecorder struct {
+	cycles int64
+}
+
+// RecordTrace: starts calling the function with an entry before every instruction the Chip8 runs
+func RecordTrace(c8 *Chip8, record func(e TraceEntry)) *TraceRecorder {
+	r := &TraceRecorder{}
+	previous := c8.Hooks.InstructionFetched
+	c8.Hooks.InstructionFetched = func(pc uint16, in Instruction) {
+		if previous != nil {
+			previous(pc, in)
+		}
+		e := NewTraceEntry(r.cycles, &c8.CurrState)
+		e.Opcode = in.Opcode
+		record(e)
+		r.cycles++
+	}
+	return r
+}
+
+// Cycles: how many instructions were recorded
+func (r *TraceRecorder) Cycles() int64 {
+	return r.cycles
+}
@@ -0,0 +1,91 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	entry := TraceEntry{Cycle: 12, PC: 0x2A4, Opcode: 0xD015, I: 0x2F0, SP: 1, DelayTimer: 0x3C, SoundTimer: 2}
+	entry.V[0x0] = 0x0A
+	entry.V[0xF] = 0x01
+	line := "12 2a4 d015 0a 00 00 00 00 00 00 00 00 00 00 00 00 00 00 01 02f0 01 3c 02"
+
+	t.Run("TraceEntry should be written in the documented line format", func(t *testing.T) {
+		assert.Equal(t, line, entry.String())
+	})
+
+	t.Run("TraceWriter and TraceReader should round trip entries and skip comments", func(t *testing.T) {
+		var b bytes.Buffer
+		w := NewTraceWriter(&b)
+		assert.NoError(t, w.Comment("test trace"))
+		assert.NoError(t, w.Write(entry))
+		next := entry
+		next.Cycle++
+		assert.NoError(t, w.Write(next))
+		assert.NoError(t, w.Flush())
+
+		r := NewTraceReader(strings.NewReader(b.String() + "\n"))
+		got, err := r.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, entry, got)
+		assert.Equal(t, 2, r.Line())
+		got, err = r.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, next, got)
+		_, err = r.Read()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("TraceReader should report the line of invalid entries", func(t *testing.T) {
+		r := NewTraceReader(strings.NewReader(line + "\n0 200 zz\n"))
+		_, err := r.Read()
+		assert.NoError(t, err)
+		_, err = r.Read()
+		assert.True(t, errors.Is(err, ErrTraceFormat))
+		assert.Contains(t, err.Error(), "line 2")
+
+		_, err = ParseTraceEntry(strings.Replace(line, "d015", "d01g", 1))
+		assert.True(t, errors.Is(err, ErrTraceFormat), "Should reject fields that aren't hexadecimal")
+		_, err = ParseTraceEntry(strings.Replace(line, " 3c ", " 13c ", 1))
+		assert.True(t, errors.Is(err, ErrTraceFormat), "Should reject timers that don't fit a byte")
+	})
+
+	t.Run("Diff should name the fields that differ", func(t *testing.T) {
+		other := entry
+		assert.Empty(t, entry.Diff(other))
+		other.V[0xA] = 7
+		other.SoundTimer = 0
+		assert.Equal(t, []string{"va", "st"}, entry.Diff(other))
+	})
+
+	t.Run("RecordTrace should record the state before every instruction", func(t *testing.T) {
+		c := New()
+		c.LoadGame([]uint8{
+			0x60, 0x05, // LD V0, 5
+			0xA3, 0x00, // LD I, 0x300
+			0x70, 0x01, // ADD V0, 1
+		})
+		var entries []TraceEntry
+		r := RecordTrace(c, func(e TraceEntry) {
+			entries = append(entries, e)
+		})
+		c.Tick(0)
+		c.Tick(0)
+		c.Tick(0)
+
+		assert.Equal(t, int64(3), r.Cycles())
+		assert.Len(t, entries, 3)
+		assert.Equal(t, TraceEntry{Cycle: 0, PC: 0x200, Opcode: 0x6005}, entries[0])
+		assert.Equal(t, uint16(0x202), entries[1].PC)
+		assert.Equal(t, uint8(5), entries[1].V[0], "Should see the result of the previous instruction")
+		assert.Equal(t, uint16(0), entries[1].I)
+		assert.Equal(t, uint16(0x300), entries[2].I)
+		assert.Equal(t, int64(2), entries[2].Cycle)
+	})
+}
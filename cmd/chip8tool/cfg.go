@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/franciscocid/chip-8/chip8"
+	"github.com/franciscocid/chip-8/chip8/analysis"
+)
+
+func cfgCommand(args []string) error {
+	flags := flag.NewFlagSet("cfg", flag.ExitOnError)
+	dotPath := flags.String("dot", "", "write the control-flow graph in Graphviz DOT to this file (- for stdout)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8tool cfg [flags] <rom>")
+	}
+	c8, rom, err := newMachine(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	g := analysis.Analyze(rom.Data, c8.Platform.LoadAddress)
+
+	if *dotPath != "" {
+		out, err := createOutput(*dotPath)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		if err := g.WriteDOT(out); err != nil {
+			return err
+		}
+		if *dotPath == "-" {
+			return nil
+		}
+	}
+
+	printAnalysis(g)
+	return nil
+}
+
+// printAnalysis: a summary of what the analysis found
+func printAnalysis(g *analysis.Graph) {
+	instructions := 0
+	for _, b := range g.Blocks {
+		instructions += len(b.Instructions)
+	}
+	fmt.Printf("%d blocks, %d instructions reachable from 0x%03X\n", len(g.Blocks), instructions, g.Start)
+
+	fmt.Printf("\nsubroutines: %d\n", len(g.Subroutines))
+	for _, s := range g.Subroutines {
+		note := ""
+		if !s.Returns {
+			note = ", never returns"
+		}
+		fmt.Printf("  0x%03X  %s, called from %s%s\n", s.Entry, plural(len(s.Blocks), "block"), addresses(s.Callers), note)
+	}
+
+	if len(g.ComputedJumps) > 0 {
+		fmt.Printf("\ncomputed jumps: %s\n", addresses(g.ComputedJumps))
+	}
+	if len(g.DeadEnds) > 0 {
+		fmt.Printf("\ndead ends: %s\n", addresses(g.DeadEnds))
+	}
+
+	if len(g.Unreachable) > 0 {
+		fmt.Println("\nunreachable:")
+		for _, r := range g.Unreachable {
+			note := ""
+			if r.Referenced {
+				note = ", data pointed by LD I"
+			}
+			fmt.Printf("  0x%03X-0x%03X  %d bytes%s\n", r.Start, r.End-1, r.End-r.Start, note)
+		}
+	}
+
+	if writes := g.SelfModifying(); len(writes) > 0 {
+		fmt.Println("\nself-modifying writes:")
+		for _, w := range writes {
+			fmt.Printf("  0x%03X  %-12s writes 0x%03X-0x%03X\n", w.PC, chip8.Disassemble(w.Opcode), w.Start, w.End-1)
+		}
+	}
+	unknown := 0
+	for _, w := range g.Writes {
+		if !w.Known {
+			unknown++
+		}
+	}
+	if unknown > 0 {
+		fmt.Printf("\n%d of %d writes go to addresses the analysis can't work out\n", unknown, len(g.Writes))
+	}
+}
+
+// addresses: the addresses in hexadecimal separated by commas
+func addresses(addrs []uint16) string {
+	if len(addrs) == 0 {
+		return "nowhere"
+	}
+	names := make([]string, len(addrs))
+	for i, addr := range addrs {
+		names[i] = fmt.Sprintf("0x%03X", addr)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/franciscocid/chip-8/chip8/analysis"
+)
+
+func decompileCommand(args []string) error {
+	flags := flag.NewFlagSet("decompile", flag.ExitOnError)
+	outPath := flags.String("o", "-", "write the source to this file (- for stdout)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8tool decompile [flags] <rom>")
+	}
+	c8, rom, err := newMachine(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return analysis.Analyze(rom.Data, c8.Platform.LoadAddress).Decompile(out)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+func heatmapCommand(args []string) error {
+	flags := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	frames := flags.Int("frames", 600, "run the ROM for this many frames")
+	every := flags.Int("every", 5, "frames on each row of the heatmap")
+	seed := flags.Int64("seed", 1, "seed of the random numbers of Cxkk")
+	pngPath := flags.String("png", "", "write the heatmap as a PNG to this file, time going down and addresses right")
+	scale := flags.Int("scale", 2, "size of each address and row in the PNG")
+	all := flags.Bool("all", false, "show the whole 4KB instead of the addresses the ROM and its accesses span")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *every < 1 {
+		return errors.New("usage: chip8tool heatmap [flags] <rom>")
+	}
+	rand.Seed(*seed)
+	c8, rom, err := newMachine(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	h := chip8.RecordMemory(c8)
+	ctl := chip8.NewController(c8)
+	for frame := 1; frame <= *frames; frame++ {
+		ctl.StepFrame()
+		if frame%*every == 0 && frame < *frames {
+			h.NextRow()
+		}
+	}
+
+	start, end := c8.Platform.LoadAddress, c8.Platform.LoadAddress+uint16(len(rom.Data))
+	if *all {
+		start, end = 0, chip8.MemorySize
+	}
+	for addr := uint16(0); addr < chip8.MemorySize; addr++ {
+		if h.Uses(addr) != 0 && addr < start {
+			start = addr
+		}
+		if h.Uses(addr) != 0 && addr >= end {
+			end = addr + 1
+		}
+	}
+
+	if *pngPath != "" {
+		file, err := os.Create(*pngPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := h.WritePNG(file, start, end, *scale); err != nil {
+			return err
+		}
+		fmt.Printf("0x%03X-0x%03X over %s of %s, %d by %d pixels\n", start, end-1, plural(h.Rows(), "row"),
+			plural(*every, "frame"), int(end-start)**scale, h.Rows()**scale)
+		for _, use := range chip8.MemoryUses {
+			c := use.Color()
+			fmt.Printf("  #%02x%02x%02x  %s\n", c.R, c.G, c.B, use)
+		}
+		fmt.Println()
+	}
+
+	printMemoryUses(h, start, end)
+	if c8.Fault != nil {
+		fmt.Printf("\nthe ROM faulted: %v\n", c8.Fault)
+	}
+	return nil
+}
+
+// printMemoryUses: the runs of addresses used the same ways
+func printMemoryUses(h *chip8.MemoryHeatmap, start, end uint16) {
+	for addr := start; addr < end; {
+		uses := h.Uses(addr)
+		next := addr + 1
+		for next < end && h.Uses(next) == uses {
+			next++
+		}
+		if uses != 0 {
+			fmt.Printf("0x%03X-0x%03X  %-5d %v\n", addr, next-1, next-addr, uses)
+		}
+		addr = next
+	}
+}
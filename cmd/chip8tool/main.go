@@ -0,0 +1,103 @@
+// chip8tool bundles the command line tools that work on chip-8 ROMs without opening a window.
+//
+// Usage:
+//
+//	chip8tool <command> [flags] <rom>
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"cfg":       {"build the control-flow graph of a ROM and report what's unreachable or self-modifying", cfgCommand},
+	"sprites":   {"extract the sprites of a ROM as a PNG atlas or assembler source", spritesCommand},
+	"decompile": {"decompile a ROM to Octo-style source with its loops, ifs and subroutines", decompileCommand},
+	"profile":   {"run a ROM headless and report the hottest addresses, operations and subroutines", profileCommand},
+	"heatmap":   {"run a ROM headless and map how it reads, writes and executes its memory over time", heatmapCommand},
+	"perf":      {"run ROMs headless and report instructions per second and allocations", perfCommand},
+	"timendus":  {"run the ROMs of the Timendus test suite and report their checks", timendusCommand},
+	"trace":     {"run a ROM headless and write a trace of every instruction", traceCommand},
+	"tracediff": {"run a ROM and report where it first diverges from a reference trace", traceDiffCommand},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: chip8tool <command> [flags] <rom>")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// newMachine: a Chip8 with the ROM at the path loaded
+func newMachine(path string) (*chip8.Chip8, *chip8.ROM, error) {
+	rom, err := chip8.LoadROMFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	c8 := chip8.New()
+	if err := c8.LoadROM(rom); err != nil {
+		return nil, nil, err
+	}
+	return c8, rom, nil
+}
+
+// runFrames: runs the machine headless for the given number of 60Hz frames, at the
+// tick rate of the ROM like the SDL frontend
+func runFrames(c8 *chip8.Chip8, frames int) {
+	ctl := chip8.NewController(c8)
+	for i := 0; i < frames; i++ {
+		ctl.StepFrame()
+	}
+}
+
+// createOutput: opens the file, or stdout if path is "-"
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
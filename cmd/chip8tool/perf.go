@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+// perfResult: what running a ROM headless cost
+type perfResult struct {
+	instructions int64
+	// idle is the ticks spent by a Dxyn waiting for the vertical blank, which run no instruction
+	idle    int64
+	elapsed time.Duration
+	allocs  uint64
+	bytes   uint64
+}
+
+func perfCommand(args []string) error {
+	flags := flag.NewFlagSet("perf", flag.ExitOnError)
+	frames := flags.Int("frames", 3600, "60Hz frames to run each ROM for")
+	history := flags.Int("history", 0, "states kept on the history while running")
+	timingName := flags.String("timing", chip8.TimingFixed.String(), "instruction timing: fixed or vip")
+	clock := flags.Float64("clock", 0, "instructions per second, 0 keeps the tick rate of the ROM")
+	backendName := flags.String("backend", chip8.BackendInterpreter.String(), "how instructions run: interpreter or jit")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("usage: chip8tool perf [flags] <rom>...")
+	}
+	timing, ok := chip8.TimingModes[*timingName]
+	if !ok {
+		return fmt.Errorf("unknown timing %q", *timingName)
+	}
+	backend, ok := chip8.Backends[*backendName]
+	if !ok {
+		return fmt.Errorf("unknown backend %q", *backendName)
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(out, "ROM\tinstructions\tidle ticks\ttime\tinstructions/s\tallocs\tbytes\t")
+	for _, path := range flags.Args() {
+		c8, rom, err := newMachine(path)
+		if err != nil {
+			return err
+		}
+		c8.Timing = timing
+		c8.Backend = backend
+		c8.HistoryLimit = *history
+		ctl := chip8.NewController(c8)
+		if *clock > 0 {
+			ctl.SetClockHz(*clock)
+		}
+
+		result := measure(ctl, c8, *frames)
+		fmt.Fprintf(out, "%s\t%d\t%d\t%v\t%.0f\t%d\t%d\t\n", rom.Name, result.instructions, result.idle,
+			result.elapsed.Round(time.Microsecond), float64(result.instructions)/result.elapsed.Seconds(),
+			result.allocs, result.bytes)
+	}
+	return out.Flush()
+}
+
+// measure: runs the frames and counts the instructions, idle ticks, time and allocations they took
+func measure(ctl *chip8.Controller, c8 *chip8.Chip8, frames int) perfResult {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	ticks, executed := c8.TickCount, c8.Executed
+	start := time.Now()
+
+	for i := 0; i < frames; i++ {
+		ctl.StepFrame()
+	}
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return perfResult{
+		instructions: c8.Executed - executed,
+		idle:         (c8.TickCount - ticks) - (c8.Executed - executed),
+		elapsed:      elapsed,
+		allocs:       after.Mallocs - before.Mallocs,
+		bytes:        after.TotalAlloc - before.TotalAlloc,
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/franciscocid/chip-8/chip8"
+	"github.com/franciscocid/chip-8/chip8/analysis"
+)
+
+func profileCommand(args []string) error {
+	flags := flag.NewFlagSet("profile", flag.ExitOnError)
+	frames := flags.Int("frames", 600, "run the ROM for this many frames")
+	seed := flags.Int64("seed", 1, "seed of the random numbers of Cxkk")
+	top := flags.Int("top", 10, "how many of the hottest addresses to list")
+	coveragePath := flags.String("coverage", "", "write the disassembly of the ROM annotated with the hits to this file (- for stdout)")
+	foldedPath := flags.String("folded", "", "write the folded call stacks for flamegraph.pl or speedscope to this file (- for stdout)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8tool profile [flags] <rom>")
+	}
+	rand.Seed(*seed)
+	c8, rom, err := newMachine(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	p := chip8.Profile(c8)
+	runFrames(c8, *frames)
+
+	start := c8.Platform.LoadAddress
+	if *coveragePath != "" {
+		if err := writeProfile(*coveragePath, func(w io.Writer) error {
+			return p.WriteCoverage(w, start, start+uint16(len(rom.Data)))
+		}); err != nil {
+			return err
+		}
+	}
+	if *foldedPath != "" {
+		if err := writeProfile(*foldedPath, p.WriteFolded); err != nil {
+			return err
+		}
+	}
+	if *coveragePath == "-" || *foldedPath == "-" {
+		return nil
+	}
+
+	fmt.Printf("%s: %s in %s\n", filepath.Base(flags.Arg(0)), plural(int(p.Instructions()), "instruction"), plural(*frames, "frame"))
+	g := analysis.Analyze(rom.Data, start)
+	found, ran := 0, 0
+	for _, b := range g.Blocks {
+		for i := range b.Instructions {
+			found++
+			if p.Hits(b.Address(i)) > 0 {
+				ran++
+			}
+		}
+	}
+	if found > 0 {
+		fmt.Printf("ran %d of the %d instructions the analysis found, %.1f%%\n", ran, found, 100*float64(ran)/float64(found))
+	}
+	if c8.Fault != nil {
+		fmt.Printf("the ROM faulted: %v\n", c8.Fault)
+	}
+	printProfile(p, c8, *top)
+	return nil
+}
+
+// writeProfile: writes a report to the file, or stdout if path is "-"
+func writeProfile(path string, write func(w io.Writer) error) error {
+	out, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return write(out)
+}
+
+// printProfile: the hottest addresses, the operations and the subroutines
+func printProfile(p *chip8.Profiler, c8 *chip8.Chip8, top int) {
+	total := float64(p.Instructions())
+	if total == 0 {
+		return
+	}
+	percent := func(hits int64) string {
+		return fmt.Sprintf("%.1f%%", 100*float64(hits)/total)
+	}
+	out := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	var hot []uint16
+	for addr := 0; addr < chip8.MemorySize; addr++ {
+		if p.Hits(uint16(addr)) > 0 {
+			hot = append(hot, uint16(addr))
+		}
+	}
+	sort.SliceStable(hot, func(i, j int) bool {
+		return p.Hits(hot[i]) > p.Hits(hot[j])
+	})
+	if len(hot) > top {
+		hot = hot[:top]
+	}
+	fmt.Fprintln(out, "\naddress\thits\t\tinstruction")
+	for _, addr := range hot {
+		opcode := uint16(c8.CurrState.Memory[addr])<<chip8.ByteSize | uint16(c8.CurrState.Memory[(addr+1)&chip8.AddressMask])
+		fmt.Fprintf(out, "0x%03X\t%d\t%s\t%s\n", addr, p.Hits(addr), percent(p.Hits(addr)), chip8.Disassemble(opcode))
+	}
+
+	fmt.Fprintln(out, "\noperation\thits")
+	for _, op := range p.Operations() {
+		fmt.Fprintf(out, "%v\t%d\t%s\n", op.Op, op.Hits, percent(op.Hits))
+	}
+
+	fmt.Fprintln(out, "\nsubroutine\tcalls\tself\t\ttotal")
+	for i, s := range p.Subroutines() {
+		name := fmt.Sprintf("sub_%03X", s.Entry)
+		if i == 0 {
+			name = "main"
+		}
+		fmt.Fprintf(out, "%s\t%d\t%d\t%s\t%d\t%s\n", name, s.Calls, s.Self, percent(s.Self), s.Total, percent(s.Total))
+	}
+	out.Flush()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"sort"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+func spritesCommand(args []string) error {
+	flags := flag.NewFlagSet("sprites", flag.ExitOnError)
+	frames := flags.Int("frames", 0, "run the ROM for this many frames and also extract the sprites it draws")
+	fonts := flags.Bool("fonts", false, "extract the built-in font instead of the ROM sprites")
+	pngPath := flags.String("png", "", "write the sprites as a PNG atlas to this file")
+	scale := flags.Int("scale", 4, "size of each sprite pixel in the PNG atlas")
+	asmPath := flags.String("asm", "-", "write the sprites as assembler db source to this file (- for stdout)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8tool sprites [flags] <rom>")
+	}
+	c8, rom, err := newMachine(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var sprites []chip8.Sprite
+	if *fonts {
+		sprites = c8.FontSprites()
+	} else {
+		recorder := chip8.RecordSprites(c8)
+		runFrames(c8, *frames)
+		sprites = mergeSprites(chip8.FindSprites(rom.Data, c8.Platform.LoadAddress), recorder.Sprites())
+	}
+
+	if *pngPath != "" {
+		file, err := os.Create(*pngPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := chip8.WriteSpritesPNG(file, sprites, *scale); err != nil {
+			return err
+		}
+	}
+
+	if *asmPath != "" {
+		out, err := createOutput(*asmPath)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		return chip8.WriteSpritesAssembly(out, sprites)
+	}
+	return nil
+}
+
+// mergeSprites: joins the sprites found statically with the ones seen at runtime,
+// preferring the runtime ones since they were actually drawn
+func mergeSprites(static, runtime []chip8.Sprite) []chip8.Sprite {
+	seen := map[uint16]bool{}
+	merged := append([]chip8.Sprite{}, runtime...)
+	for _, sprite := range runtime {
+		seen[sprite.Address] = true
+	}
+	for _, sprite := range static {
+		if !seen[sprite.Address] {
+			merged = append(merged, sprite)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Address < merged[j].Address
+	})
+	return merged
+}
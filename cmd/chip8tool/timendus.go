@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+func timendusCommand(args []string) error {
+	flags := flag.NewFlagSet("timendus", flag.ExitOnError)
+	verbose := flags.Bool("v", false, "print every check, not only the ones that failed")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8tool timendus [flags] <dir with the suite ROMs>")
+	}
+
+	ran, failed := 0, 0
+	for _, test := range chip8.TimendusTests {
+		rom, err := chip8.LoadROMFile(filepath.Join(flags.Arg(0), test.File))
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("%-20s skipped, %s not found\n", test.Name, test.File)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := chip8.RunTimendusTest(rom, test)
+		if err != nil {
+			return err
+		}
+		ran++
+		switch {
+		case result.Fault != nil:
+			fmt.Printf("%-20s FAULT %v\n", test.Name, result.Fault)
+		case len(result.Checks) == 0:
+			fmt.Printf("%-20s FAIL no results on the screen\n", test.Name)
+		case result.Passed():
+			fmt.Printf("%-20s ok %d checks\n", test.Name, len(result.Checks))
+		default:
+			fmt.Printf("%-20s FAIL %d of %d checks\n", test.Name, len(result.Failed()), len(result.Checks))
+		}
+		if !result.Passed() {
+			failed++
+		}
+		for _, check := range result.Checks {
+			if *verbose || !check.Passed {
+				fmt.Printf("  %v\n", check)
+			}
+		}
+	}
+
+	if ran == 0 {
+		return fmt.Errorf("no ROMs of the suite found in %s", flags.Arg(0))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, ran)
+	}
+	return nil
+}
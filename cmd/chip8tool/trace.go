@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/franciscocid/chip-8/chip8"
+)
+
+func traceCommand(args []string) error {
+	flags := flag.NewFlagSet("trace", flag.ExitOnError)
+	frames := flags.Int("frames", 60, "run the ROM for this many frames")
+	seed := flags.Int64("seed", 1, "seed of the random numbers of Cxkk, so traces can be compared")
+	outPath := flags.String("o", "-", "write the trace to this file (- for stdout)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8tool trace [flags] <rom>")
+	}
+	rand.Seed(*seed)
+	c8, _, err := newMachine(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := chip8.NewTraceWriter(out)
+	w.Comment(fmt.Sprintf("%s, %d frames, seed %d", filepath.Base(flags.Arg(0)), *frames, *seed))
+	w.Comment("cycle pc opcode v0-vf i sp dt st")
+	var werr error
+	chip8.RecordTrace(c8, func(e chip8.TraceEntry) {
+		if werr == nil {
+			werr = w.Write(e)
+		}
+	})
+	runFrames(c8, *frames)
+	if werr != nil {
+		return werr
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if c8.Fault != nil {
+		return fmt.Errorf("the ROM faulted: %w", c8.Fault)
+	}
+	return nil
+}
+
+// traceDivergence: the first entry of a run that differs from the reference trace
+type traceDivergence struct {
+	line     int
+	want     chip8.TraceEntry
+	got      chip8.TraceEntry
+	previous []chip8.TraceEntry
+}
+
+func traceDiffCommand(args []string) error {
+	flags := flag.NewFlagSet("tracediff", flag.ExitOnError)
+	frames := flags.Int("frames", 3600, "give up after running the ROM for this many frames")
+	seed := flags.Int64("seed", 1, "seed of the random numbers of Cxkk")
+	context := flags.Int("context", 8, "how many matching entries to show before the divergence")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return errors.New("usage: chip8tool tracediff [flags] <reference trace> <rom>")
+	}
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rand.Seed(*seed)
+	c8, _, err := newMachine(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	reference := chip8.NewTraceReader(file)
+	var (
+		previous   []chip8.TraceEntry
+		divergence *traceDivergence
+		readErr    error
+		done       bool
+		matched    int
+	)
+	chip8.RecordTrace(c8, func(got chip8.TraceEntry) {
+		if done {
+			return
+		}
+		want, err := reference.Read()
+		if err != nil {
+			readErr, done = err, true
+			return
+		}
+		if len(want.Diff(got)) > 0 {
+			divergence = &traceDivergence{line: reference.Line(), want: want, got: got, previous: previous}
+			done = true
+			return
+		}
+		matched++
+		if *context > 0 {
+			if len(previous) == *context {
+				previous = append(previous[:0], previous[1:]...)
+			}
+			previous = append(previous, got)
+		}
+	})
+
+	ctl := chip8.NewController(c8)
+	for frame := 0; frame < *frames && !done && c8.Fault == nil; frame++ {
+		ctl.StepFrame()
+	}
+
+	switch {
+	case divergence != nil:
+		printDivergence(os.Stdout, divergence)
+		return fmt.Errorf("the trace diverges at cycle %d", divergence.got.Cycle)
+	case errors.Is(readErr, io.EOF):
+		fmt.Printf("the %d entries of the reference trace match\n", matched)
+		return nil
+	case readErr != nil:
+		return readErr
+	case c8.Fault != nil:
+		return fmt.Errorf("the ROM faulted after matching %d entries: %w", matched, c8.Fault)
+	default:
+		fmt.Printf("no divergence in %d frames, %d entries matched\n", *frames, matched)
+		return nil
+	}
+}
+
+// printDivergence: the entries before the divergence, then what the reference expected and what
+// the ROM did, with the fields that differ marked under the line
+func printDivergence(w io.Writer, d *traceDivergence) {
+	fields := d.want.Diff(d.got)
+	for _, e := range d.previous {
+		fmt.Fprintf(w, "  %v\n", e)
+	}
+	fmt.Fprintf(w, "- %v\n", d.want)
+	fmt.Fprintf(w, "+ %v\n", d.got)
+	fmt.Fprintf(w, "  %s\n", markFields(d.got, fields))
+	fmt.Fprintf(w, "reference line %d, %s: %s\n", d.line, plural(len(fields), "field"), strings.Join(fields, ", "))
+	fmt.Fprintf(w, "  %03x  %s\n", d.got.PC, chip8.Disassemble(d.got.Opcode))
+}
+
+// markFields: a line of ^ under the fields of the entry that are named
+func markFields(e chip8.TraceEntry, names []string) string {
+	fields := strings.Fields(e.String())
+	order := []string{"cycle", "pc", "opcode"}
+	for x := 0; x < 0x10; x++ {
+		order = append(order, fmt.Sprintf("v%x", x))
+	}
+	order = append(order, "i", "sp", "dt", "st")
+
+	marked := map[string]bool{}
+	for _, name := range names {
+		marked[name] = true
+	}
+	var b strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		mark := " "
+		if marked[order[i]] {
+			mark = "^"
+		}
+		b.WriteString(strings.Repeat(mark, len(field)))
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
@@ -1,39 +1,97 @@
 package main
 
 import (
-	"io"
+	"flag"
+	"fmt"
 	"math/rand"
 	"os"
+	"strings"
 
 	"github.com/franciscocid/chip-8/chip8"
 )
 
 func main() {
+	fontName := flag.String("font", chip8.DefaultFont.Name, "built-in font: "+strings.Join(chip8.FontSetNames(), ", "))
+	fontFile := flag.String("font-file", "", "load a custom raw binary font instead of a built-in one")
+	fontAddress := flag.Uint("font-address", uint(chip8.FontsStartAddress), "memory address the font is loaded at")
+	platformName := flag.String("platform", chip8.PlatformCHIP8.Name, "platform the ROM was written for, when it isn't in the ROM database: "+strings.Join(chip8.PlatformNames(), ", "))
+	timingName := flag.String("timing", chip8.TimingFixed.String(), "instruction timing: fixed (tick rate instructions per frame) or vip (COSMAC VIP machine cycles)")
+	backendName := flag.String("backend", chip8.BackendInterpreter.String(), "how instructions run: interpreter or jit (translated blocks, used when -log is off)")
+	romDir := flag.String("roms", "roms", "directory listed by the launcher")
+	useDatabase := flag.Bool("db", true, "select the platform, quirks, speed, font, keys and palette from the ROM database")
+	logInstructions := flag.Bool("log", false, "print every instruction run")
+	flag.Parse()
+
+	setFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	platform, ok := chip8.Platforms[*platformName]
+	if !ok {
+		panic(fmt.Sprintf("unknown platform %q", *platformName))
+	}
+
+	font, ok := chip8.FontSets[*fontName]
+	if !ok {
+		panic(fmt.Sprintf("unknown font %q", *fontName))
+	}
+	if *fontFile != "" {
+		var err error
+		if font, err = chip8.LoadFontFile(*fontFile); err != nil {
+			panic(err)
+		}
+	}
+
+	timing, ok := chip8.TimingModes[*timingName]
+	if !ok {
+		panic(fmt.Sprintf("unknown timing %q", *timingName))
+	}
+	backend, ok := chip8.Backends[*backendName]
+	if !ok {
+		panic(fmt.Sprintf("unknown backend %q", *backendName))
+	}
+
 	c8 := chip8.New()
+	c8.Platform = platform
+	c8.Timing = timing
+	c8.Backend = backend
+	if *logInstructions {
+		c8.Log = os.Stdout
+	}
+	if *fontAddress >= chip8.MemorySize {
+		panic(fmt.Sprintf("font address 0x%x is past the end of memory", *fontAddress))
+	}
+	// Selected before the graphics are made so the launcher goes back to it for the ROMs the database doesn't know
+	if err := c8.SelectFont(font, uint16(*fontAddress)); err != nil {
+		panic(err)
+	}
 	g := chip8.NewGraphicsSDL(c8)
+	g.ROMDir = *romDir
+	g.UseROMDatabase = *useDatabase
 	rand.Seed(1)
 
-	// file, err := os.Open("roms/ibm.ch8")
-	// file, err := os.Open("roms/tank.ch8")
-	// file, err := os.Open("roms/pong.ch8")
-	// file, err := os.Open("roms/maze.ch8")
-	file, err := os.Open("roms/tetris.ch8")
-	// file, err := os.Open("roms/tictactoe.ch8")
-	// file, err := os.Open("roms/space.ch8")
-	// file, err := os.Open("roms/invaders.ch8")
-	if err != nil {
-		panic(err)
+	// Without a ROM on the command line the launcher opens to pick one
+	if flag.NArg() > 0 {
+		rom, err := chip8.LoadROMFile(flag.Arg(0))
+		if err != nil {
+			panic(err)
+		}
+		if !*useDatabase {
+			rom.Info = nil
+		}
+		if err := c8.LoadROM(rom); err != nil {
+			panic(err)
+		}
 	}
-	romData, err := io.ReadAll(file)
-	if err != nil {
-		panic(err)
+	// The font recommended by the ROM database is kept unless one is asked for
+	if c8.ROM != nil && c8.ROM.Info != nil && (setFlags["font"] || setFlags["font-file"] || setFlags["font-address"]) {
+		if err := c8.SelectFont(font, uint16(*fontAddress)); err != nil {
+			panic(err)
+		}
 	}
 
-	c8.LoadFonts()
-	c8.LoadGame(romData)
-
-	err = g.Run()
-	if err != nil {
+	if err := g.Run(); err != nil {
 		panic(err)
 	}
 }